@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+func format(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := fs.Bool("w", false, "write result to the source file instead of standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: braincode fmt [flags] [task.ml ...]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Reformats markleft documents. Without files, standard input is formatted.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		if *write {
+			return fmt.Errorf("cannot use -w with standard input")
+		}
+		return ml.Format(os.Stdin, os.Stdout)
+	}
+
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var out bytes.Buffer
+		if err := ml.Format(bytes.NewReader(src), &out); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if !*write {
+			if _, err := os.Stdout.Write(out.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+		if bytes.Equal(src, out.Bytes()) {
+			continue
+		}
+		if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func generate(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	dir := fs.String("o", "", "write every test to `dir`/G.T.in instead of printing quoted tests")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: braincode gen [flags] task")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Generates tests of a task and checks them with its validator.")
+		fmt.Fprintln(fs.Output(), "Task is a markleft document, a problem package directory or a zipped package.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	_, p, err := readTask(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	tests, err := p.GenerateInput()
+	if err != nil {
+		return err
+	}
+
+	if *dir != "" {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	for i, group := range tests {
+		for k, input := range group {
+			if p.Validator != nil {
+				if err := p.Validator.ValidateInput(input); err != nil {
+					return fmt.Errorf("invalid test %d.%d: %w", i+1, k+1, err)
+				}
+			}
+
+			if *dir == "" {
+				fmt.Printf("test %d.%d: %q\n", i+1, k+1, input)
+				continue
+			}
+			name := filepath.Join(*dir, fmt.Sprintf("%d.%d.in", i+1, k+1))
+			if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/ml"
+	"github.com/TrueHopolok/braincode-/judge/pack"
+)
+
+// readTask reads a markleft task, a problem package directory or a zipped package, and builds its problem.
+func readTask(path string) (ml.Document, judge.Problem, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return doc, judge.Problem{}, fmt.Errorf("%s: %w", path, err)
+	}
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		return doc, judge.Problem{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, p, nil
+}
+
+func readDocument(path string) (ml.Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ml.Document{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ml.Document{}, err
+	}
+	switch {
+	case info.IsDir():
+		return pack.ReadDir(path)
+	case filepath.Ext(path) == ".zip":
+		return pack.ReadZip(f, info.Size())
+	default:
+		return ml.Parse(f)
+	}
+}
+
+func judgeSolution(args []string) error {
+	fs := flag.NewFlagSet("judge", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "print verdicts of accepted tests too")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: braincode judge [flags] task solution.bf")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Judges a solution against all tests of a task and prints verdicts of every group.")
+		fmt.Fprintln(fs.Output(), "Task is a markleft document, a problem package directory or a zipped package.")
+		fmt.Fprintln(fs.Output(), "Exits with status 1 unless the solution earns full score.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	_, p, err := readTask(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	solution, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	j := judge.NewJudge(runtime.NumCPU())
+	defer j.Close()
+
+	verdicts := j.JudgeContext(ctx, p, string(solution))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(verdicts) == 1 && len(verdicts[0]) == 1 {
+		if v := verdicts[0][0]; v.Status == judge.StatusCompilationFailed || v.Status == judge.StatusSourceSizeLimit {
+			// not tied to any test
+			fmt.Println(v.Error())
+			return errFailed
+		}
+	}
+
+	breakdown := p.Scoring.Score(verdicts)
+	for i, group := range verdicts {
+		if i < len(breakdown.Points) {
+			fmt.Printf("group %d: %g of %g points\n", i+1, breakdown.Points[i], breakdown.MaxPoints[i])
+		}
+		for k, v := range group {
+			if v.Status == judge.StatusAccept && !*verbose {
+				continue
+			}
+			test := fmt.Sprintf("test %d.%d", i+1, k+1)
+			if seed, ok := judge.TestSeed(p.InputGenerator, i, k); ok {
+				test += fmt.Sprintf(" (seed %d)", seed)
+			}
+			fmt.Printf("  %s: %v (steps %d, memory %d, %v)\n", test, v.Error(), v.Steps, v.Memory, v.Time)
+		}
+	}
+	fmt.Printf("score: %g of %g\n", breakdown.Total(), breakdown.Max())
+
+	if breakdown.Total() < breakdown.Max() || breakdown.Max() == 0 {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command braincode is a command line tool for working with braincode problems offline.
+//
+// Usage:
+//
+//	braincode <command> [arguments]
+//
+// Run "braincode <command> -h" for help on a command.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+)
+
+type command struct {
+	run   func(args []string) error
+	usage string // Short description shown in the command list.
+}
+
+var commands = map[string]command{
+	"fmt":    {format, "reformat markleft documents"},
+	"gen":    {generate, "print generated tests of a task"},
+	"judge":  {judgeSolution, "judge a solution against a task"},
+	"run":    {runProgram, "run a brainfunk program with standard input and output"},
+	"stress": {stress, "compare two brainfunk programs on random inputs"},
+}
+
+var (
+	// errUsage is returned after usage of a command has been printed.
+	errUsage = errors.New("invalid usage")
+
+	// errFailed is returned when a command has reported a failure itself.
+	errFailed = errors.New("failed")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: braincode <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "braincode: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	switch err := cmd.run(os.Args[2:]); {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case errors.Is(err, errFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "braincode %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+func runProgram(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	steps := fs.Int("steps", 0, "step limit (default unlimited)")
+	memory := fs.Int("memory", 0, "memory limit in bytes (default unlimited)")
+	timeLimit := fs.Duration("time", 0, "wall-clock time limit (default unlimited)")
+	dialect := fs.String("dialect", "", "brainfunk dialect, for example \"cell=16 eof=zero\"")
+	verbose := fs.Bool("v", false, "print used steps and memory to standard error")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: braincode run [flags] program.bf < input")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Runs a brainfunk program with standard input and output.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	d, err := bf.ParseDialect(*dialect)
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	bc, err := bf.CompileDialect(string(source), -1, d)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+
+	stepLimit, memoryLimit := math.MaxInt, math.MaxInt
+	if *steps > 0 {
+		stepLimit = *steps
+	}
+	if *memory > 0 {
+		memoryLimit = *memory
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	s := bf.NewState(bc, bufio.NewReader(os.Stdin), out, stepLimit, memoryLimit)
+	runErr := s.RunContext(ctx)
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "steps: %d, memory: %d\n", stepLimit-s.RemainingSteps(), s.UsedMemory())
+	}
+	if errors.Is(runErr, io.EOF) {
+		runErr = errors.New("read past the end of input")
+	}
+	if runErr != nil {
+		if pos, ok := bc.Position(s.ErrorInstruction()); ok {
+			return fmt.Errorf("%w at %v", runErr, pos)
+		}
+		return runErr
+	}
+	return nil
+}
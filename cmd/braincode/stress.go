@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+func stress(args []string) error {
+	fs := flag.NewFlagSet("stress", flag.ContinueOnError)
+	tests := fs.Int("n", 1000, "number of generated inputs")
+	seed := fs.Int64("seed", 1, "seed of the first input, following inputs use consecutive seeds")
+	steps := fs.Int("steps", 0, "step limit of a single run (default 1000000)")
+	memory := fs.Int("memory", 0, "memory limit of a single run in bytes (default 64 MiB)")
+	timeLimit := fs.Duration("time", 0, "time limit of a candidate run (default 2s)")
+	dialect := fs.String("dialect", "", "brainfunk dialect, for example \"cell=16 eof=zero\"")
+	compare := fs.String("compare", "", "output comparison options, for example \"tokens ignore-case\"")
+	params := fs.String("params", "", "parameters passed to test_data, for example \"n=100 max=255\"")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: braincode stress [flags] reference.bf candidate.bf generator.lua")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Runs both programs on inputs returned by test_data(seed, params) function of the lua script")
+		fmt.Fprintln(fs.Output(), "and reports the first input they disagree on. The function is the same one used by .generate tasks.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return errUsage
+	}
+	if *tests <= 0 {
+		return fmt.Errorf("number of inputs must be positive, got %d", *tests)
+	}
+
+	reference, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	candidate, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	spec, err := lua.ParseGroupSpecs(*params)
+	if err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+	if len(spec) > 1 || (len(spec) == 1 && (spec[0].Tests != 1 || spec[0].Seed != 0)) {
+		return fmt.Errorf("params: must be a single line without tests and seed, use -n and -seed instead")
+	}
+	var groupParams map[string]string
+	if len(spec) == 1 {
+		groupParams = spec[0].Params
+	}
+
+	gen, err := lua.NewSeedGenerator(string(source), groupParams)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(2), err)
+	}
+
+	cfg := judge.StressConfig{
+		Tests:  *tests,
+		Seed:   *seed,
+		Steps:  *steps,
+		Memory: *memory,
+		Time:   *timeLimit,
+	}
+	if cfg.Dialect, err = bf.ParseDialect(*dialect); err != nil {
+		return err
+	}
+	if cfg.Comparison, err = judge.ParseComparison(*compare); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	j := judge.NewJudge(runtime.NumCPU())
+	defer j.Close()
+
+	res, err := j.Stress(ctx, string(reference), string(candidate), gen, cfg)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		fmt.Printf("no difference found in %d tests\n", *tests)
+		return nil
+	}
+
+	fmt.Printf("seed:      %d\n", res.Seed)
+	fmt.Printf("input:     %q\n", res.Input)
+	fmt.Printf("verdict:   %v\n", res.Verdict.Error())
+	fmt.Printf("reference: %q\n", res.Reference)
+	fmt.Printf("candidate: %q\n", res.Candidate)
+	return errFailed
+}
@@ -2,9 +2,13 @@ package bf_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/TrueHopolok/braincode-/judge/bf"
 )
@@ -145,6 +149,28 @@ func TestStepLimit(t *testing.T) {
 	}
 }
 
+func TestRunContext(t *testing.T) {
+	t.Parallel()
+
+	bc, err := bf.Compile("+[[-]+]", -1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s := bf.NewState(bc, nil, nil, math.MaxInt, 100)
+
+	if err := s.RunContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v want deadline exceeded", err)
+	}
+
+	if !s.Finished() {
+		t.Error("state is not finished after cancellation")
+	}
+}
+
 func TestMarshal(t *testing.T) {
 	t.Parallel()
 
@@ -170,3 +196,75 @@ func TestMarshal(t *testing.T) {
 		t.Errorf("before %v after %v", bc, bc2)
 	}
 }
+
+func TestSourceMap(t *testing.T) {
+	t.Parallel()
+
+	const source = "[comment <]\n+++ a\n  >>\n<<<"
+
+	bc, err := bf.Compile(source, -1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	s := bf.NewState(bc, nil, nil, 100, 100)
+	if err := s.Run(); err != bf.RuntimeErrorHeadUnderflow {
+		t.Fatalf("err = %v want head underflow", err)
+	}
+
+	want := bf.Position{Offset: len(source) - 1, Line: 4, Column: 3}
+	if pos, _ := bc.Position(s.ErrorInstruction()); pos != want {
+		t.Errorf("position %v want %v", pos, want)
+	}
+
+	bin, err := bc.MarshalBinaryWithSourceMap()
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	var bc2 bf.ByteCode
+	if err := bc2.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if !reflect.DeepEqual(bc, bc2) {
+		t.Errorf("before %v after %v", bc, bc2)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	const source = "+++[>++[>+<-]<-]"
+
+	bc, err := bf.Compile(source, -1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	s := bf.NewState(bc, nil, nil, 1000, 100)
+	s.EnableProfiling()
+	if err := s.Run(); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	p := s.Profile()
+
+	if p.Steps != 1000-s.RemainingSteps() {
+		t.Errorf("steps %d want %d", p.Steps, 1000-s.RemainingSteps())
+	}
+	if p.UsedMemory != 3 {
+		t.Errorf("used memory %d want 3", p.UsedMemory)
+	}
+
+	want := []bf.LoopProfile{
+		{Start: 3, End: 15, Depth: 0, Iterations: 3, Steps: p.Steps - 3},
+		{Start: 7, End: 12, Depth: 1, Iterations: 6, Steps: 3 * (1 + 2*5)},
+	}
+	if !reflect.DeepEqual(p.Loops, want) {
+		t.Errorf("loops %+v want %+v", p.Loops, want)
+	}
+	if p.Hits[8] != 6 {
+		t.Errorf("hits of inner loop body %d want 6", p.Hits[8])
+	}
+}
@@ -9,6 +9,16 @@ import (
 // ByteCode is immutable, so it is safe to use from multiple goroutines.
 type ByteCode struct {
 	ops []opjump
+
+	offsets []int // Byte offset of each instruction in the source code.
+	lines   []int // Byte offset of each line start in the source code.
+	dumps   []int // Sorted instruction indices preceded by [DumpMarker].
+
+	dialect Dialect
+
+	// Optimized program used by [State.Run], see [optimize].
+	prog  []instr
+	entry []int
 }
 
 // Op is a brainfunk operations.
@@ -24,6 +34,9 @@ const (
 	OpLoopStart Op = '[' // Start of loop. Loop acts as a while (head != 0) loop.
 	OpLoopEnd   Op = ']' // End of loop.
 
+	// DumpMarker is not an operation. It is recorded by [Compile] and used by [Debugger] to pause execution.
+	DumpMarker = '#'
+
 	opMax = max(OpLeft, OpRight, OpIncrement, OpDecrement, OpIncrement, OpOutput, OpLoopStart, OpLoopEnd)
 )
 
@@ -66,6 +79,11 @@ func (j opjump) Addr() uint32 {
 // Compile extracts byte code from a source string.
 //
 // Initial comment loop will be stripped.
+// Positions of [DumpMarker] characters outside of it are recorded for [Debugger].
+//
+// Runs of operations, as well as common loops such as [-], [->+<] and [>], are additionally folded
+// into an optimized program used by [State.Run]. Folding never changes observable behavior
+// or step accounting.
 //
 // Instruction limit may be set to limit length of byte code. Negative limit disables it.
 // In that case, [CompilationError] with kind [CompilationInstructionLimit] is never returned.
@@ -76,6 +94,8 @@ func Compile(source string, instructionLimit int) (ByteCode, error) {
 		instructionLimit = math.MaxInt
 	}
 
+	lines := lineStarts(source)
+
 	was := len(source)
 	source = stripCommentLoop(source)
 	off := was - len(source)
@@ -83,8 +103,17 @@ func Compile(source string, instructionLimit int) (ByteCode, error) {
 	var instructions []opjump
 	var openLoops []uint32
 	var byteOff []int
+	var offsets []int
+	var dumps []int
 
 	for i, r := range source {
+		if r == DumpMarker {
+			if len(dumps) == 0 || dumps[len(dumps)-1] != len(instructions) {
+				dumps = append(dumps, len(instructions))
+			}
+			continue
+		}
+
 		if r > rune(opMax) || !validOp[r] {
 			continue
 		}
@@ -97,6 +126,8 @@ func Compile(source string, instructionLimit int) (ByteCode, error) {
 			}
 		}
 
+		offsets = append(offsets, i+off)
+
 		b := Op(r)
 		switch b {
 		case OpLoopStart:
@@ -133,7 +164,16 @@ func Compile(source string, instructionLimit int) (ByteCode, error) {
 		}
 	}
 
-	return ByteCode{ops: instructions}, nil
+	prog, entry := optimize(instructions)
+
+	return ByteCode{
+		ops:     instructions,
+		offsets: offsets,
+		lines:   lines,
+		dumps:   dumps,
+		prog:    prog,
+		entry:   entry,
+	}, nil
 }
 
 var opIndex = [opMax + 1]byte{
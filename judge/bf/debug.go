@@ -0,0 +1,191 @@
+package bf
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Len returns number of instructions in the byte code.
+func (bc ByteCode) Len() int {
+	return len(bc.ops)
+}
+
+// Op returns operation of i-th instruction. Panics if i is out of range.
+func (bc ByteCode) Op(i int) Op {
+	return opAt(bc.ops, i)
+}
+
+// Offset returns byte offset of i-th instruction in the source code passed to [Compile].
+// Returns -1 if i is out of range.
+//
+// Byte code decoded by [ByteCode.UnmarshalBinary] has offsets relative to [ByteCode.String].
+func (bc ByteCode) Offset(i int) int {
+	if i < 0 || i >= len(bc.offsets) {
+		return -1
+	}
+	return bc.offsets[i]
+}
+
+// Instruction returns index of the next instruction to be executed.
+// It is equal to byte code length if program finished.
+func (s *State) Instruction() int {
+	return s.instruction
+}
+
+// Head returns current head position.
+func (s *State) Head() int {
+	return s.head
+}
+
+// Tape returns a copy of cell values in range [from, to).
+// Cells that were never allocated are returned as zeroes.
+//
+// Head position and cells left of the first one may only be negative for [TapeBiInfinite] tapes.
+func (s *State) Tape(from, to int) []uint32 {
+	if to <= from {
+		return nil
+	}
+	res := make([]uint32, to-from)
+	for i := range res {
+		res[i] = s.cell(from + i)
+	}
+	return res
+}
+
+// StopReason reports why [Debugger] paused execution.
+type StopReason int
+
+const (
+	StopFinished   StopReason = iota // Program finished, see [State.Error].
+	StopBreakpoint                   // Next instruction has a breakpoint.
+	StopDump                         // Next instruction is preceded by [DumpMarker] in the source code.
+	StopOp                           // Last executed instruction matched one of requested operations.
+)
+
+// Debugger executes a brainfunk program step-by-step, pausing on breakpoints and dump markers.
+//
+// Debugger is *not* safe for concurrent use.
+type Debugger struct {
+	State
+
+	code        ByteCode
+	breakpoints map[int]bool
+}
+
+// NewDebugger creates a new [Debugger]. Arguments have the same meaning as in [NewState].
+func NewDebugger(
+	code ByteCode,
+	r io.Reader,
+	w io.Writer,
+	stepLimit int,
+	memoryLimit int,
+) *Debugger {
+	return &Debugger{
+		State:       NewState(code, r, w, stepLimit, memoryLimit),
+		code:        code,
+		breakpoints: make(map[int]bool),
+	}
+}
+
+// ByteCode returns byte code being debugged.
+func (d *Debugger) ByteCode() ByteCode {
+	return d.code
+}
+
+// SetBreakpoint pauses execution before i-th instruction.
+func (d *Debugger) SetBreakpoint(i int) {
+	d.breakpoints[i] = true
+}
+
+// SetSourceBreakpoint pauses execution before first instruction at or after byte offset in the source code.
+// Returns index of the instruction, or false if there is no such instruction.
+func (d *Debugger) SetSourceBreakpoint(offset int) (int, bool) {
+	i, _ := slices.BinarySearch(d.code.offsets, offset)
+	if i >= len(d.code.offsets) {
+		return 0, false
+	}
+	d.SetBreakpoint(i)
+	return i, true
+}
+
+// ClearBreakpoint removes breakpoint from i-th instruction. It is a no-op if breakpoint is not set.
+func (d *Debugger) ClearBreakpoint(i int) {
+	delete(d.breakpoints, i)
+}
+
+// Breakpoints returns sorted instruction indices of all breakpoints.
+func (d *Debugger) Breakpoints() []int {
+	res := make([]int, 0, len(d.breakpoints))
+	for i := range d.breakpoints {
+		res = append(res, i)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Continue runs the program until next breakpoint, dump marker or until it finishes.
+// At least one instruction is executed, unless program is already finished.
+func (d *Debugger) Continue() StopReason {
+	return d.ContinueUntil()
+}
+
+// ContinueUntil is like [Debugger.Continue], but additionally pauses right after executing
+// any instruction with one of provided operations.
+//
+// For example, ContinueUntil(OpOutput) runs until program writes a byte.
+func (d *Debugger) ContinueUntil(ops ...Op) StopReason {
+	for !d.Finished() {
+		op := d.code.Op(d.instruction)
+		if err := d.Step(); err != nil {
+			break
+		}
+
+		if slices.Contains(ops, op) {
+			return StopOp
+		}
+		if d.Finished() {
+			break
+		}
+		if d.breakpoints[d.instruction] {
+			return StopBreakpoint
+		}
+		if _, found := slices.BinarySearch(d.code.dumps, d.instruction); found {
+			return StopDump
+		}
+	}
+	return StopFinished
+}
+
+// Dump returns a human readable description of current state.
+// Tape is shown in range [head - radius, head + radius].
+//
+//	instruction 12 (line 2, column 5), head 2, steps left 988
+//	0000: 00 05 [0a] 00 00
+func (d *Debugger) Dump(radius int) string {
+	b := new(strings.Builder)
+
+	fmt.Fprintf(b, "instruction %d", d.instruction)
+	if pos, ok := d.code.Position(d.instruction); ok {
+		fmt.Fprintf(b, " (%v)", pos)
+	}
+	fmt.Fprintf(b, ", head %d, steps left %d\n", d.head, d.stepLimit)
+
+	from := d.head - radius
+	if d.dialect.Tape != TapeBiInfinite {
+		from = max(from, 0)
+	}
+	digits := d.dialect.cellBytes() * 2
+	fmt.Fprintf(b, "%04x:", from)
+	for i, c := range d.Tape(from, d.head+radius+1) {
+		if from+i == d.head {
+			fmt.Fprintf(b, " [%0*x]", digits, c)
+		} else {
+			fmt.Fprintf(b, " %0*x", digits, c)
+		}
+	}
+	b.WriteByte('\n')
+
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package bf_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+func TestDebugger(t *testing.T) {
+	t.Parallel()
+
+	const source = "+++ >++#\n> +++++ [<+>-] <."
+
+	bc, err := bf.Compile(source, -1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	d := bf.NewDebugger(bc, nil, out, 1000, 100)
+
+	loop := strings.IndexByte(source, '[')
+	i, ok := d.SetSourceBreakpoint(loop - 1)
+	if !ok {
+		t.Fatal("breakpoint not set")
+	}
+	if bc.Op(i) != bf.OpLoopStart {
+		t.Fatalf("breakpoint at %c want %c", bc.Op(i), bf.OpLoopStart)
+	}
+
+	if r := d.Continue(); r != bf.StopDump {
+		t.Fatalf("stop reason %v want %v", r, bf.StopDump)
+	}
+	if have, want := d.Tape(0, 3), []uint32{3, 2, 0}; !slices.Equal(have, want) {
+		t.Errorf("tape %v want %v", have, want)
+	}
+	if d.Head() != 1 {
+		t.Errorf("head %d want 1", d.Head())
+	}
+
+	if r := d.Continue(); r != bf.StopBreakpoint {
+		t.Fatalf("stop reason %v want %v", r, bf.StopBreakpoint)
+	}
+	if d.Instruction() != i {
+		t.Errorf("instruction %d want %d", d.Instruction(), i)
+	}
+	t.Log(d.Dump(2))
+
+	d.ClearBreakpoint(i)
+
+	if r := d.ContinueUntil(bf.OpOutput); r != bf.StopOp {
+		t.Fatalf("stop reason %v want %v", r, bf.StopOp)
+	}
+	if out.String() != "\x07" {
+		t.Errorf("output %q want %q", out, "\x07")
+	}
+
+	if r := d.Continue(); r != bf.StopFinished {
+		t.Fatalf("stop reason %v want %v", r, bf.StopFinished)
+	}
+	if err := d.Error(); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+package bf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes runtime semantics of a brainfunk program.
+//
+// Zero value is the default dialect: 8 bit wrapping cells, right-infinite tape that errors on underflow
+// and input that terminates the program with [io.EOF].
+type Dialect struct {
+	CellBits int          // Cell width: 8, 16 or 32. Zero means 8.
+	Overflow OverflowMode // What happens when a cell value goes out of range.
+	EOF      EOFMode      // What [OpInput] does after input ended.
+	Tape     TapeMode     // Tape topology.
+	TapeSize int          // Number of cells for [TapeBounded] and [TapeCircular] tapes. Ignored otherwise.
+}
+
+// OverflowMode is a cell overflow behavior of a [Dialect].
+type OverflowMode int
+
+const (
+	OverflowWrap  OverflowMode = iota // Cell values wrap around.
+	OverflowError                     // Overflow terminates the program with [RuntimeErrorCellOverflow].
+)
+
+// EOFMode is an end of input behavior of a [Dialect].
+type EOFMode int
+
+const (
+	EOFError     EOFMode = iota // Program terminates with [io.EOF].
+	EOFUnchanged                // Cell under head is left unchanged.
+	EOFZero                     // Zero is written to the cell under head.
+	EOFMinusOne                 // Maximum cell value (-1) is written to the cell under head.
+)
+
+// TapeMode is a tape topology of a [Dialect].
+type TapeMode int
+
+const (
+	TapeRightInfinite TapeMode = iota // Tape grows to the right, moving left of the first cell is an error.
+	TapeBounded                       // Tape has [Dialect.TapeSize] cells, moving out of it is an error.
+	TapeCircular                      // Tape has [Dialect.TapeSize] cells and wraps around at both ends.
+	TapeBiInfinite                    // Tape grows in both directions.
+)
+
+var (
+	overflowNames = []string{OverflowWrap: "wrap", OverflowError: "error"}
+	eofNames      = []string{EOFError: "error", EOFUnchanged: "unchanged", EOFZero: "zero", EOFMinusOne: "minus-one"}
+	tapeNames     = []string{TapeRightInfinite: "right", TapeBounded: "bounded", TapeCircular: "circular", TapeBiInfinite: "infinite"}
+)
+
+// Validate reports whether dialect is supported.
+func (d Dialect) Validate() error {
+	switch d.CellBits {
+	case 0, 8, 16, 32:
+	default:
+		return fmt.Errorf("unsupported cell width %d", d.CellBits)
+	}
+	if d.Overflow < 0 || int(d.Overflow) >= len(overflowNames) {
+		return fmt.Errorf("unsupported overflow mode %d", d.Overflow)
+	}
+	if d.EOF < 0 || int(d.EOF) >= len(eofNames) {
+		return fmt.Errorf("unsupported EOF mode %d", d.EOF)
+	}
+	if d.Tape < 0 || int(d.Tape) >= len(tapeNames) {
+		return fmt.Errorf("unsupported tape mode %d", d.Tape)
+	}
+	if (d.Tape == TapeBounded || d.Tape == TapeCircular) && d.TapeSize < 1 {
+		return errors.New("bounded and circular tapes must have a positive size")
+	}
+	return nil
+}
+
+// String formats dialect in a format accepted by [ParseDialect]. Default values are omitted.
+func (d Dialect) String() string {
+	var fields []string
+	if d.CellBits != 0 && d.CellBits != 8 {
+		fields = append(fields, "cell="+strconv.Itoa(d.CellBits))
+	}
+	if d.Overflow != OverflowWrap {
+		fields = append(fields, "overflow="+name(overflowNames, d.Overflow))
+	}
+	if d.EOF != EOFError {
+		fields = append(fields, "eof="+name(eofNames, d.EOF))
+	}
+	if d.Tape != TapeRightInfinite {
+		fields = append(fields, "tape="+name(tapeNames, d.Tape))
+	}
+	if d.Tape == TapeBounded || d.Tape == TapeCircular {
+		fields = append(fields, "size="+strconv.Itoa(d.TapeSize))
+	}
+	return strings.Join(fields, " ")
+}
+
+func name[T ~int](names []string, v T) string {
+	if v < 0 || int(v) >= len(names) {
+		return strconv.Itoa(int(v))
+	}
+	return names[v]
+}
+
+// ParseDialect parses whitespace separated key=value pairs. Omitted keys have default values.
+//
+//	cell=8|16|32
+//	overflow=wrap|error
+//	eof=error|unchanged|zero|minus-one
+//	tape=right|bounded|circular|infinite
+//	size=<number of cells>
+//
+// Empty string is the default dialect.
+func ParseDialect(s string) (Dialect, error) {
+	var d Dialect
+	for _, field := range strings.Fields(s) {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return Dialect{}, fmt.Errorf("dialect field %q must be a key=value pair", field)
+		}
+
+		var err error
+		switch key {
+		case "cell":
+			d.CellBits, err = strconv.Atoi(value)
+		case "overflow":
+			d.Overflow, err = parseName[OverflowMode](overflowNames, value)
+		case "eof":
+			d.EOF, err = parseName[EOFMode](eofNames, value)
+		case "tape":
+			d.Tape, err = parseName[TapeMode](tapeNames, value)
+		case "size":
+			d.TapeSize, err = strconv.Atoi(value)
+		default:
+			err = errors.New("unknown key")
+		}
+		if err != nil {
+			return Dialect{}, fmt.Errorf("dialect field %q: %w", field, err)
+		}
+	}
+	return d, d.Validate()
+}
+
+func parseName[T ~int](names []string, s string) (T, error) {
+	for i, n := range names {
+		if n == s {
+			return T(i), nil
+		}
+	}
+	return 0, fmt.Errorf("expected one of %s", strings.Join(names, ", "))
+}
+
+// CompileDialect is [Compile] followed by [ByteCode.WithDialect].
+func CompileDialect(source string, instructionLimit int, d Dialect) (ByteCode, error) {
+	bc, err := Compile(source, instructionLimit)
+	if err != nil {
+		return bc, err
+	}
+	return bc.WithDialect(d)
+}
+
+// WithDialect returns a copy of byte code, that will be executed using dialect d.
+// Dialect is not preserved by [ByteCode.MarshalBinary] and [ByteCode.MarshalText].
+func (bc ByteCode) WithDialect(d Dialect) (ByteCode, error) {
+	if err := d.Validate(); err != nil {
+		return bc, err
+	}
+	if d.CellBits == 0 {
+		d.CellBits = 8
+	}
+	if d == (Dialect{CellBits: 8}) {
+		d = Dialect{}
+	}
+	bc.dialect = d
+	return bc, nil
+}
+
+// Dialect returns dialect of the byte code.
+func (bc ByteCode) Dialect() Dialect {
+	return bc.dialect
+}
+
+// cellBytes returns size of a single cell in bytes.
+func (d Dialect) cellBytes() int {
+	return max(d.CellBits, 8) / 8
+}
+
+// mask returns maximum cell value.
+func (d Dialect) mask() uint32 {
+	return uint32(1<<max(d.CellBits, 8) - 1)
+}
+
+// cell returns value of a cell at position i. Cells that were never allocated are zero.
+func (s *State) cell(i int) uint32 {
+	w := s.dialect.cellBytes()
+
+	mem := s.memory
+	if i < 0 {
+		mem, i = s.left, -i-1
+	}
+	if (i+1)*w > len(mem) {
+		return 0
+	}
+
+	switch w {
+	case 1:
+		return uint32(mem[i])
+	case 2:
+		return uint32(binary.LittleEndian.Uint16(mem[i*2:]))
+	default:
+		return binary.LittleEndian.Uint32(mem[i*4:])
+	}
+}
+
+// setCell sets value of an allocated cell at position i.
+func (s *State) setCell(i int, v uint32) {
+	w := s.dialect.cellBytes()
+
+	mem := s.memory
+	if i < 0 {
+		mem, i = s.left, -i-1
+	}
+
+	switch w {
+	case 1:
+		mem[i] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(mem[i*2:], uint16(v))
+	default:
+		binary.LittleEndian.PutUint32(mem[i*4:], v)
+	}
+}
+
+// allocate ensures that a cell at position i is allocated.
+func (s *State) allocate(i int) error {
+	w := s.dialect.cellBytes()
+
+	need := len(s.memory)
+	needLeft := len(s.left)
+	if i >= 0 {
+		need = max(need, (i+1)*w)
+	} else {
+		needLeft = max(needLeft, -i*w)
+	}
+
+	if need+needLeft > s.memoryLimit {
+		return RuntimeErrorMemoryLimit
+	}
+
+	if n := need - len(s.memory); n > 0 {
+		s.memory = append(s.memory, make([]byte, n)...)
+	}
+	if n := needLeft - len(s.left); n > 0 {
+		s.left = append(s.left, make([]byte, n)...)
+	}
+	return nil
+}
+
+// stepDialect executes a single instruction for non default dialects.
+// Step limit and finished state must be checked by the caller.
+func (s *State) stepDialect() error {
+	d := s.dialect
+
+	switch s.bytecode[s.instruction].Op() {
+	case OpIncrement, OpDecrement:
+		v := s.cell(s.head)
+		if s.bytecode[s.instruction].Op() == OpIncrement {
+			if v == d.mask() && d.Overflow == OverflowError {
+				return RuntimeErrorCellOverflow
+			}
+			v = (v + 1) & d.mask()
+		} else {
+			if v == 0 && d.Overflow == OverflowError {
+				return RuntimeErrorCellOverflow
+			}
+			v = (v - 1) & d.mask()
+		}
+		s.setCell(s.head, v)
+
+	case OpLeft:
+		head := s.head - 1
+		switch d.Tape {
+		case TapeRightInfinite, TapeBounded:
+			if head < 0 {
+				return RuntimeErrorHeadUnderflow
+			}
+		case TapeCircular:
+			if head < 0 {
+				head = d.TapeSize - 1
+			}
+		}
+		if err := s.allocate(head); err != nil {
+			return err
+		}
+		s.head = head
+
+	case OpRight:
+		head := s.head + 1
+		switch d.Tape {
+		case TapeBounded:
+			if head >= d.TapeSize {
+				return RuntimeErrorHeadOverflow
+			}
+		case TapeCircular:
+			if head >= d.TapeSize {
+				head = 0
+			}
+		}
+		if err := s.allocate(head); err != nil {
+			return err
+		}
+		s.head = head
+
+	case OpInput:
+		c, err := s.r.ReadByte()
+		switch {
+		case err == nil:
+			s.setCell(s.head, uint32(c))
+		case err != io.EOF || d.EOF == EOFError:
+			return err
+		case d.EOF == EOFZero:
+			s.setCell(s.head, 0)
+		case d.EOF == EOFMinusOne:
+			s.setCell(s.head, d.mask())
+		}
+
+	case OpOutput:
+		if err := s.w.WriteByte(byte(s.cell(s.head))); err != nil {
+			return err
+		}
+
+	case OpLoopStart:
+		addr := s.bytecode[s.instruction].Addr()
+		if int(addr) > s.instruction {
+			// [
+			if s.cell(s.head) == 0 {
+				s.instruction = int(addr)
+			}
+		} else {
+			// ]
+			if s.cell(s.head) != 0 {
+				s.instruction = int(addr)
+			}
+		}
+
+	default:
+		panic("unexpected bf.Op")
+	}
+
+	s.instruction++
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package bf_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+func TestDialect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect string
+		source  string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"default wraps", "", "-.", "", "\xff", nil},
+		{"default eof", "", ",", "", "", io.EOF},
+		{"eof unchanged", "eof=unchanged", "+++,.", "", "\x03", nil},
+		{"eof zero", "eof=zero", "+++,.", "", "\x00", nil},
+		{"eof minus one", "eof=minus-one", ",+[-.,+]", "ab", "ab", nil},
+		{"16 bit output", "cell=16", "-.", "", "\xff", nil},
+		{"256 wraps 8 bit", "", "++++++++++++++++[>++++++++++++++++<-]>[[-]+.[-]]", "", "", nil},
+		{"256 fits 16 bit", "cell=16", "++++++++++++++++[>++++++++++++++++<-]>[[-]+.[-]]", "", "\x01", nil},
+		{"overflow error", "overflow=error", "-", "", "", bf.RuntimeErrorCellOverflow},
+		{"overflow error 32 bit", "cell=32 overflow=error", "+-+-.", "", "\x00", nil},
+		{"bounded", "tape=bounded size=3", ">>>", "", "", bf.RuntimeErrorHeadOverflow},
+		{"bounded underflow", "tape=bounded size=3", "<", "", "", bf.RuntimeErrorHeadUnderflow},
+		{"circular", "tape=circular size=3", "+<.<.<.", "", "\x00\x00\x01", nil},
+		{"bi infinite", "tape=infinite", "<<<+++[>>>+<<<-]>>>.", "", "\x03", nil},
+		{"memory limit", "cell=32", ">>>", "", "", bf.RuntimeErrorMemoryLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := bf.ParseDialect(tt.dialect)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if s := d.String(); s != tt.dialect {
+				t.Errorf("String() = %q want %q", s, tt.dialect)
+			}
+
+			bc, err := bf.CompileDialect(tt.source, -1, d)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			out := new(bytes.Buffer)
+			s := bf.NewState(bc, strings.NewReader(tt.input), out, 10000, 12)
+			if err := s.Run(); err != tt.wantErr {
+				t.Errorf("err = %v want %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output %q want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDialectErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"cell=12", "tape=circular", "eof", "eof=maybe", "color=blue"} {
+		if _, err := bf.ParseDialect(s); err == nil {
+			t.Errorf("ParseDialect(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -35,6 +35,8 @@ const (
 	RuntimeErrorHeadUnderflow RuntimeError = iota // Head executed [OpLeft] while pointing to leftmost byte.
 	RuntimeErrorMemoryLimit                       // Program allocated limit+1 bytes.
 	RuntimeErrorStepLimit                         // Program did not terminate after limit steps.
+	RuntimeErrorCellOverflow                      // Cell value went out of range, see [OverflowError].
+	RuntimeErrorHeadOverflow                      // Head moved past the end of a [TapeBounded] tape.
 )
 
 func (e RuntimeError) Error() string {
@@ -45,6 +47,10 @@ func (e RuntimeError) Error() string {
 		return "runtime error: memory limit"
 	case RuntimeErrorStepLimit:
 		return "runtime error: step limit"
+	case RuntimeErrorCellOverflow:
+		return "runtime error: cell overflow"
+	case RuntimeErrorHeadOverflow:
+		return "runtime error: head overflow"
 	default:
 		panic(fmt.Sprintf("unexpected bf.RuntimeError: %#v", e))
 	}
@@ -0,0 +1,321 @@
+package bf
+
+import (
+	"slices"
+)
+
+// instrKind is a kind of an optimized instruction.
+type instrKind byte
+
+const (
+	instrAdd       instrKind = iota // Add arg to the value under head. Folded run of [OpIncrement] and [OpDecrement].
+	instrMove                       // Move head by arg. Folded run of [OpLeft] or [OpRight].
+	instrInput                      // Same as [OpInput].
+	instrOutput                     // Same as [OpOutput].
+	instrLoopStart                  // Jump to arg if value under head is zero.
+	instrLoopEnd                    // Jump to arg if value under head is not zero.
+	instrMulLoop                    // Balanced loop without IO, such as [-] or [->+<]. See [instr.body].
+	instrScan                       // Loop of form [>] or [<<], arg is a signed stride.
+)
+
+// instr is a single instruction of an optimized program.
+//
+// Each instruction covers naive byte code range [start, end).
+// Running an instruction always takes the same number of steps as running the naive byte code it covers.
+type instr struct {
+	kind       instrKind
+	arg        int
+	start, end int
+
+	// Only set for instrMulLoop.
+	delta     byte        // Change of value under head during single iteration. Either 1 or 255.
+	low, high int         // Lowest and highest head offset reached during single iteration.
+	body      []cellDelta // Changes to the other cells during single iteration.
+}
+
+type cellDelta struct {
+	offset int
+	delta  byte
+}
+
+// optimize folds naive byte code into a program for [State.Run].
+//
+// Returned entry maps each naive instruction index to the instruction of the optimized program
+// which starts at it, or -1 if optimized program has no such instruction.
+// Entry has an additional element for the end of the program.
+func optimize(ops []opjump) (prog []instr, entry []int) {
+	entry = make([]int, len(ops)+1)
+	for i := range entry {
+		entry[i] = -1
+	}
+
+	var openLoops []int
+
+	for i := 0; i < len(ops); {
+		entry[i] = len(prog)
+
+		switch op := opAt(ops, i); op {
+		case OpIncrement, OpDecrement:
+			j := i
+			var delta byte
+			for ; j < len(ops); j++ {
+				if o := opAt(ops, j); o == OpIncrement {
+					delta++
+				} else if o == OpDecrement {
+					delta--
+				} else {
+					break
+				}
+			}
+			prog = append(prog, instr{kind: instrAdd, arg: int(delta), start: i, end: j})
+			i = j
+
+		case OpLeft, OpRight:
+			j := i
+			for j < len(ops) && opAt(ops, j) == op {
+				j++
+			}
+			dist := j - i
+			if op == OpLeft {
+				dist = -dist
+			}
+			prog = append(prog, instr{kind: instrMove, arg: dist, start: i, end: j})
+			i = j
+
+		case OpInput:
+			prog = append(prog, instr{kind: instrInput, start: i, end: i + 1})
+			i++
+
+		case OpOutput:
+			prog = append(prog, instr{kind: instrOutput, start: i, end: i + 1})
+			i++
+
+		case OpLoopStart:
+			if in, ok := foldLoop(ops, i); ok {
+				prog = append(prog, in)
+				i = in.end
+				continue
+			}
+			openLoops = append(openLoops, len(prog))
+			prog = append(prog, instr{kind: instrLoopStart, start: i, end: i + 1})
+			i++
+
+		case OpLoopEnd:
+			start := openLoops[len(openLoops)-1]
+			openLoops = openLoops[:len(openLoops)-1]
+			prog[start].arg = len(prog)
+			prog = append(prog, instr{kind: instrLoopEnd, arg: start, start: i, end: i + 1})
+			i++
+		}
+	}
+
+	entry[len(ops)] = len(prog)
+
+	return prog, entry
+}
+
+// foldLoop tries to replace loop starting at ops[i] with a single instruction.
+func foldLoop(ops []opjump, i int) (instr, bool) {
+	end := int(ops[i].Addr())
+	body := ops[i+1 : end]
+	if len(body) == 0 {
+		return instr{}, false
+	}
+
+	var pos, low, high int
+	sameMoves := true
+	deltas := map[int]byte{}
+
+	for j := range body {
+		switch opAt(body, j) {
+		case OpIncrement:
+			deltas[pos]++
+			sameMoves = false
+		case OpDecrement:
+			deltas[pos]--
+			sameMoves = false
+		case OpLeft:
+			pos--
+			sameMoves = sameMoves && pos < 0
+		case OpRight:
+			pos++
+			sameMoves = sameMoves && pos > 0
+		default:
+			return instr{}, false
+		}
+		low = min(low, pos)
+		high = max(high, pos)
+	}
+
+	if sameMoves {
+		return instr{kind: instrScan, arg: pos, start: i, end: end + 1}, true
+	}
+
+	if pos != 0 || (deltas[0] != 1 && deltas[0] != 255) {
+		return instr{}, false
+	}
+
+	in := instr{
+		kind:  instrMulLoop,
+		start: i,
+		end:   end + 1,
+		delta: deltas[0],
+		low:   low,
+		high:  high,
+	}
+	for off, d := range deltas {
+		if off != 0 && d != 0 {
+			in.body = append(in.body, cellDelta{offset: off, delta: d})
+		}
+	}
+	slices.SortFunc(in.body, func(l, r cellDelta) int { return l.offset - r.offset })
+
+	return in, true
+}
+
+// opAt returns an operation at index i, distinguishing between [OpLoopStart] and [OpLoopEnd].
+func opAt(ops []opjump, i int) Op {
+	op := ops[i].Op()
+	if op == OpLoopStart && int(ops[i].Addr()) < i {
+		return OpLoopEnd
+	}
+	return op
+}
+
+// runFast executes at most limit instructions of the optimized program.
+// It stops early if the program terminates or an instruction cannot be executed
+// without diverging from the naive semantics. In the latter case a single naive step is made,
+// which is guaranteed to make progress.
+//
+// Returns number of executed instructions, which is always at least one.
+//
+// Must be called only if current instruction is an entry point of the optimized program.
+func (s *State) runFast(limit int) (executed int) {
+	prog := s.prog
+
+	for pc := s.entry[s.instruction]; pc < len(prog); pc++ {
+		in := &prog[pc]
+
+		if executed >= max(limit, 1) {
+			s.instruction = in.start
+			return executed
+		}
+		executed++
+
+		switch in.kind {
+		case instrAdd:
+			if !s.consume(in.end - in.start) {
+				s.fallback(in)
+				return executed
+			}
+			s.memory[s.head] += byte(in.arg)
+
+		case instrMove:
+			head := s.head + in.arg
+			if head < 0 || head >= s.memoryLimit || !s.consume(in.end-in.start) {
+				s.fallback(in)
+				return executed
+			}
+			s.grow(head)
+			s.head = head
+
+		case instrInput, instrOutput:
+			s.fallback(in)
+			if s.Finished() {
+				return executed
+			}
+
+		case instrLoopStart:
+			if !s.consume(1) {
+				s.fallback(in)
+				return executed
+			}
+			if s.memory[s.head] == 0 {
+				pc = in.arg
+			}
+
+		case instrLoopEnd:
+			if !s.consume(1) {
+				s.fallback(in)
+				return executed
+			}
+			if s.memory[s.head] != 0 {
+				pc = in.arg
+			}
+
+		case instrMulLoop:
+			v := s.memory[s.head]
+			if v == 0 {
+				if !s.consume(1) {
+					s.fallback(in)
+					return executed
+				}
+				continue
+			}
+
+			n := int(v)
+			if in.delta == 1 {
+				n = 256 - n
+			}
+
+			if s.head+in.low < 0 || s.head+in.high >= s.memoryLimit || !s.consume(1+n*(in.end-in.start-1)) {
+				s.fallback(in)
+				return executed
+			}
+
+			s.grow(s.head + in.high)
+			for _, c := range in.body {
+				s.memory[s.head+c.offset] += byte(n) * c.delta
+			}
+			s.memory[s.head] = 0
+
+		case instrScan:
+			perIteration := in.end - in.start - 1
+			head := s.head
+			n := 0
+			for head < len(s.memory) && s.memory[head] != 0 {
+				head += in.arg
+				n++
+				if head < 0 || head >= s.memoryLimit || 1+n*perIteration > s.stepLimit {
+					s.fallback(in)
+					return executed
+				}
+			}
+
+			if !s.consume(1 + n*perIteration) {
+				s.fallback(in)
+				return executed
+			}
+			s.grow(head)
+			s.head = head
+
+		default:
+			panic("unexpected bf.instrKind")
+		}
+	}
+
+	s.instruction = len(s.bytecode)
+	return executed
+}
+
+// consume subtracts steps from the step limit if there are enough steps left.
+func (s *State) consume(steps int) bool {
+	if steps > s.stepLimit {
+		return false
+	}
+	s.stepLimit -= steps
+	return true
+}
+
+// fallback makes a single naive step at the start of in.
+func (s *State) fallback(in *instr) {
+	s.instruction = in.start
+	s.Step()
+}
+
+// grow ensures that memory contains head.
+func (s *State) grow(head int) {
+	if n := head + 1 - len(s.memory); n > 0 {
+		s.memory = append(s.memory, make([]byte, n)...)
+	}
+}
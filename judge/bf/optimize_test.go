@@ -0,0 +1,102 @@
+package bf
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptimizedMatchesNaive(t *testing.T) {
+	t.Parallel()
+
+	sources := []string{
+		"+++++[-]",
+		"+++++[+]",
+		"+++[->+++<]>.",
+		"++[->>+<<]>>.",
+		"+++++[->+>++<<]>.>.",
+		"+>+>+>+<<<[>]<.",
+		">>>+<<<+[>>>]",
+		"+[<]",
+		"+++[->+<<+>]",
+		"---[>+<+++]",
+		"+[-<+>]",
+		">,[>,]<[.<]",
+		"++++++++[>++++[>++>+++>+++>+<<<<-]>+>+>->>+[<]<-]>>.>---.+++++++..+++.>>.<-.<.+++.------.--------.>>+.>++.",
+		">>>>>>>>>>",
+		"<",
+		"+[>+]",
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		sources = append(sources, randomProgram(rng, 30))
+	}
+
+	for _, source := range sources {
+		for _, d := range []Dialect{{}, {EOF: EOFZero}} {
+			bc, err := CompileDialect(source, -1, d)
+			if err != nil {
+				t.Fatalf("%q: err = %v", source, err)
+			}
+
+			for _, steps := range []int{0, 1, 3, 10, 57, 1000, 100000} {
+				for _, memory := range []int{1, 3, 8, 1000} {
+					compareRuns(t, bc, source, steps, memory)
+				}
+			}
+		}
+	}
+}
+
+func randomProgram(rng *rand.Rand, n int) string {
+	const ops = "+-<>.,"
+
+	b := new(strings.Builder)
+	depth := 0
+	for range n {
+		switch x := rng.IntN(10); {
+		case x == 0:
+			b.WriteByte('[')
+			depth++
+		case x == 1 && depth > 0:
+			b.WriteByte(']')
+			depth--
+		default:
+			b.WriteByte(ops[rng.IntN(len(ops))])
+		}
+	}
+	b.WriteString(strings.Repeat("]", depth))
+	return b.String()
+}
+
+func compareRuns(t *testing.T, bc ByteCode, source string, steps, memory int) {
+	t.Helper()
+
+	const input = "\x03abc\x00\xff"
+
+	naiveOut := new(bytes.Buffer)
+	naive := NewState(bc, strings.NewReader(input), naiveOut, steps, memory)
+	for !naive.Finished() {
+		naive.Step()
+	}
+
+	fastOut := new(bytes.Buffer)
+	fast := NewState(bc, strings.NewReader(input), fastOut, steps, memory)
+	fast.Run()
+
+	if naive.Error() != fast.Error() {
+		t.Errorf("%q (steps %d, memory %d): err = %v want %v", source, steps, memory, fast.Error(), naive.Error())
+	}
+	if naiveOut.String() != fastOut.String() {
+		t.Errorf("%q (steps %d, memory %d): output = %q want %q", source, steps, memory, fastOut, naiveOut)
+	}
+	if naive.RemainingSteps() != fast.RemainingSteps() {
+		t.Errorf("%q (steps %d, memory %d): remaining steps = %d want %d", source, steps, memory, fast.RemainingSteps(), naive.RemainingSteps())
+	}
+	if naive.head != fast.head || !reflect.DeepEqual(naive.memory, fast.memory) {
+		t.Errorf("%q (steps %d, memory %d): tape = %v (head %d) want %v (head %d)", source, steps, memory, fast.memory, fast.head, naive.memory, naive.head)
+	}
+}
@@ -0,0 +1,68 @@
+package bf
+
+// Profile contains execution statistics of a brainfunk program. See [State.EnableProfiling].
+//
+// Use [ByteCode.Position] to map instructions back to the source code, for example to render a heat map.
+type Profile struct {
+	Hits       []int         // Hits[i] is number of times i-th instruction was executed.
+	Steps      int           // Total number of executed steps.
+	UsedMemory int           // Maximum tape extent, same as [State.UsedMemory].
+	Loops      []LoopProfile // All loops in order of their starting instruction.
+}
+
+// LoopProfile contains execution statistics of a single loop.
+type LoopProfile struct {
+	Start      int // Index of [OpLoopStart] instruction.
+	End        int // Index of matching [OpLoopEnd] instruction.
+	Depth      int // Nesting depth, 0 for outermost loops.
+	Iterations int // Number of completed iterations.
+	Steps      int // Steps spent in the loop, including brackets and nested loops.
+}
+
+// EnableProfiling makes state count executed instructions. It must be called before the first step.
+//
+// Profiling disables optimizations of [State.Run], so programs run several times slower.
+func (s *State) EnableProfiling() {
+	if s.hits == nil {
+		s.hits = make([]int, len(s.bytecode))
+	}
+}
+
+// Profile returns execution statistics collected so far.
+// If profiling was not enabled with [State.EnableProfiling], only memory usage is reported.
+func (s *State) Profile() Profile {
+	p := Profile{
+		Hits:       append([]int(nil), s.hits...),
+		UsedMemory: s.UsedMemory(),
+	}
+
+	// prefix[i] is a sum of hits before i-th instruction
+	prefix := make([]int, len(p.Hits)+1)
+	for i, h := range p.Hits {
+		prefix[i+1] = prefix[i] + h
+	}
+	p.Steps = prefix[len(p.Hits)]
+
+	depth := 0
+	for i := range s.bytecode {
+		switch opAt(s.bytecode, i) {
+		case OpLoopStart:
+			end := int(s.bytecode[i].Addr())
+			p.Loops = append(p.Loops, LoopProfile{
+				Start: i,
+				End:   end,
+				Depth: depth,
+			})
+			if p.Hits != nil {
+				l := &p.Loops[len(p.Loops)-1]
+				l.Iterations = p.Hits[end]
+				l.Steps = prefix[end+1] - prefix[i]
+			}
+			depth++
+		case OpLoopEnd:
+			depth--
+		}
+	}
+
+	return p
+}
@@ -7,10 +7,14 @@ package bf
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"io"
 )
 
+// checkInterval is a number of instructions executed by [State.RunContext] between context checks.
+const checkInterval = 1 << 14
+
 // State stores runtime brainfunk state.
 //
 // Zero value for state is a terminated program.
@@ -25,10 +29,21 @@ type State struct {
 	bytecode    []opjump
 	instruction int
 
+	prog  []instr
+	entry []int
+
 	memory []byte
+	left   []byte // Cells left of the first one, only used by [TapeBiInfinite].
 	head   int
 
-	err error
+	dialect   Dialect
+	custom    bool // Dialect is not default, so [State.stepDialect] is used.
+	optimized bool // Dialect is compatible with [State.runFast].
+
+	err            error
+	errInstruction int
+
+	hits []int // Only set when profiling.
 }
 
 // NewState creates a new [State].
@@ -42,6 +57,8 @@ type State struct {
 // If w implements [io.ByteWriter] it is used instead.
 //
 // Step and memory limits must be set. Negative limits terminated the program immediately.
+//
+// Program is executed using dialect of the byte code, see [ByteCode.WithDialect].
 func NewState(
 	code ByteCode,
 	r io.Reader,
@@ -68,13 +85,21 @@ func NewState(
 
 	s := State{
 		bytecode:    code.ops,
+		prog:        code.prog,
+		entry:       code.entry,
 		r:           rr,
 		w:           ww,
 		stepLimit:   stepLimit,
 		memoryLimit: memoryLimit,
-		memory:      []byte{0},
+		memory:      make([]byte, code.dialect.cellBytes()),
+		dialect:     code.dialect,
+		custom:      code.dialect != Dialect{},
 	}
-	if memoryLimit < 1 {
+	// input is always executed by Step, so EOF mode does not matter
+	fastDialect := code.dialect
+	fastDialect.EOF = EOFError
+	s.optimized = fastDialect == Dialect{} || fastDialect == Dialect{CellBits: 8}
+	if memoryLimit < len(s.memory) {
 		s.finish(RuntimeErrorMemoryLimit)
 	}
 	return s
@@ -85,7 +110,7 @@ func NewState(
 // Only tape memory is considered, any input or output bytes are not.
 // Byte code length is also not counted.
 func (s *State) UsedMemory() int {
-	return len(s.memory)
+	return len(s.memory) + len(s.left)
 }
 
 // RemainingSteps returns maximum number of steps program can take before hitting the step limit.
@@ -97,9 +122,35 @@ func (s *State) RemainingSteps() int {
 //
 // [Finished] is guaranteed to return true after a call to Run.
 //
+// Run executes the optimized program produced by [Compile] whenever possible.
+// Result, step accounting and memory usage are always the same as if [Step] was called repeatedly.
+//
 // See [Step] for more info.
 func (s *State) Run() error {
-	for ; !s.Finished(); s.Step() {
+	return s.RunContext(context.Background())
+}
+
+// RunContext is like [Run], but checks ctx periodically.
+//
+// If ctx is done before program completes, the program is terminated and ctx.Err() is returned.
+// Blocking reads and writes are not interrupted.
+func (s *State) RunContext(ctx context.Context) error {
+	budget := checkInterval
+	for !s.Finished() {
+		if budget <= 0 {
+			if err := ctx.Err(); err != nil {
+				s.finish(err)
+				return err
+			}
+			budget = checkInterval
+		}
+
+		if s.entry[s.instruction] >= 0 && s.hits == nil && s.optimized {
+			budget -= s.runFast(budget)
+		} else {
+			s.Step()
+			budget--
+		}
 	}
 	return s.Error()
 }
@@ -114,6 +165,17 @@ func (s *State) Error() error {
 	return s.err
 }
 
+// ErrorInstruction returns index of the instruction that caused [State.Error].
+// Returns -1 if there is no error.
+//
+// Use [ByteCode.Position] to locate the instruction in the source code.
+func (s *State) ErrorInstruction() int {
+	if s.err == nil {
+		return -1
+	}
+	return s.errInstruction
+}
+
 // Step executes a single brainfunk instruction.
 //
 // Error is returned in 2 cases:
@@ -134,6 +196,18 @@ func (s *State) Step() error {
 	}
 	s.stepLimit--
 
+	if s.hits != nil {
+		s.hits[s.instruction]++
+	}
+
+	if s.custom {
+		if err := s.stepDialect(); err != nil {
+			s.finish(err)
+			return err
+		}
+		return nil
+	}
+
 	switch s.bytecode[s.instruction].Op() {
 	case OpDecrement:
 		s.memory[s.head]--
@@ -197,6 +271,7 @@ func (s *State) Step() error {
 
 func (s *State) finish(err error) {
 	s.err = err
+	s.errInstruction = s.instruction
 	s.instruction = len(s.bytecode)
 }
 
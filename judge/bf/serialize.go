@@ -38,7 +38,7 @@ func (bc ByteCode) AppendBinary(b []byte) ([]byte, error) {
 	return b, nil
 }
 
-// UnmarshalBinary decodes data generated by [ByteCode.MarshalBinary] or [ByteCode.UnmarshalBinary]
+// UnmarshalBinary decodes data generated by [ByteCode.MarshalBinary] or [ByteCode.MarshalBinaryWithSourceMap].
 //
 // Implements [encoding.BinaryUnmarshaler].
 func (bc *ByteCode) UnmarshalBinary(data []byte) error {
@@ -53,9 +53,12 @@ func (bc *ByteCode) UnmarshalBinary(data []byte) error {
 
 	data = data[n:]
 
-	if len(data) != int(size+7)/8*3 {
+	packed := int(size+7) / 8 * 3
+	if len(data) < packed {
 		return errors.New("bad data length")
 	}
+	sourceMap := data[packed:]
+	data = data[:packed]
 
 	ops := make([]Op, 0, len(data)/3*8)
 
@@ -86,7 +89,13 @@ func (bc *ByteCode) UnmarshalBinary(data []byte) error {
 		return err
 	}
 
-	bc.ops = bc2.ops
+	if len(sourceMap) > 0 {
+		if err := bc2.unmarshalSourceMap(sourceMap); err != nil {
+			return err
+		}
+	}
+
+	*bc = bc2
 	return nil
 }
 
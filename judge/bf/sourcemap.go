@@ -0,0 +1,137 @@
+package bf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Position is a location in the source code.
+type Position struct {
+	Offset int // Byte offset, starting at 0.
+	Line   int // Line number, starting at 1.
+	Column int // Byte offset in the line, starting at 1.
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// Position returns location of i-th instruction in the source code passed to [Compile].
+// Returns false if i is out of range.
+//
+// Byte code decoded by [ByteCode.UnmarshalBinary] without a source map has positions
+// relative to [ByteCode.String].
+func (bc ByteCode) Position(i int) (Position, bool) {
+	off := bc.Offset(i)
+	if off < 0 {
+		return Position{}, false
+	}
+
+	line, found := slices.BinarySearch(bc.lines, off)
+	if !found {
+		line--
+	}
+
+	return Position{
+		Offset: off,
+		Line:   line + 1,
+		Column: off - bc.lines[line] + 1,
+	}, true
+}
+
+// lineStarts returns byte offsets of all lines in source.
+func lineStarts(source string) []int {
+	res := []int{0}
+	for i := range len(source) {
+		if source[i] == '\n' {
+			res = append(res, i+1)
+		}
+	}
+	return res
+}
+
+// MarshalBinaryWithSourceMap is like [ByteCode.MarshalBinary], but also stores the source map,
+// so [ByteCode.Position] is preserved after [ByteCode.UnmarshalBinary].
+func (bc ByteCode) MarshalBinaryWithSourceMap() ([]byte, error) {
+	return bc.AppendBinaryWithSourceMap(nil)
+}
+
+// AppendBinaryWithSourceMap is like [ByteCode.AppendBinary], but also stores the source map,
+// so [ByteCode.Position] is preserved after [ByteCode.UnmarshalBinary].
+func (bc ByteCode) AppendBinaryWithSourceMap(b []byte) ([]byte, error) {
+	b, err := bc.AppendBinary(b)
+	if err != nil {
+		return b, err
+	}
+
+	// both offsets and line starts are increasing, so only differences are stored
+	b = binary.AppendUvarint(b, uint64(len(bc.lines)))
+	prev := 0
+	for _, l := range bc.lines {
+		b = binary.AppendUvarint(b, uint64(l-prev))
+		prev = l
+	}
+
+	prev = 0
+	for _, off := range bc.offsets {
+		b = binary.AppendUvarint(b, uint64(off-prev))
+		prev = off
+	}
+
+	return b, nil
+}
+
+// unmarshalSourceMap decodes source map appended by [ByteCode.AppendBinaryWithSourceMap].
+func (bc *ByteCode) unmarshalSourceMap(data []byte) error {
+	next := func() (int, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errors.New("bad source map")
+		}
+		data = data[n:]
+		return int(v), nil
+	}
+
+	n, err := next()
+	if err != nil {
+		return err
+	}
+	if n > len(data) {
+		return errors.New("bad source map line count")
+	}
+
+	lines := make([]int, n)
+	prev := 0
+	for i := range lines {
+		d, err := next()
+		if err != nil {
+			return err
+		}
+		prev += d
+		lines[i] = prev
+	}
+
+	offsets := make([]int, len(bc.ops))
+	prev = 0
+	for i := range offsets {
+		d, err := next()
+		if err != nil {
+			return err
+		}
+		prev += d
+		offsets[i] = prev
+	}
+
+	if len(data) > 0 {
+		return fmt.Errorf("source map contains %d trailing junk bytes", len(data))
+	}
+	if len(lines) == 0 || lines[0] != 0 {
+		return errors.New("bad source map line starts")
+	}
+
+	bc.lines = lines
+	bc.offsets = offsets
+	return nil
+}
@@ -76,6 +76,7 @@ type serializedBFSolution struct {
 	B bf.ByteCode // Field names are also encoded in GOB, so they are one byte long.
 	S int
 	M int
+	D bf.Dialect
 }
 
 func (b bfSolution) MarshalBinary() ([]byte, error) { return b.AppendBinary(nil) }
@@ -85,6 +86,7 @@ func (b bfSolution) AppendBinary(buf []byte) ([]byte, error) {
 		B: b.bc,
 		S: b.steps,
 		M: b.memory,
+		D: b.bc.Dialect(),
 	})
 	return buffer.Bytes(), err
 }
@@ -97,14 +99,23 @@ func (b *bfSolution) UnmarshalBinary(buf []byte) error {
 	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&data); err != nil {
 		return err
 	}
-	b.bc = data.B
+	bc, err := data.B.WithDialect(data.D)
+	if err != nil {
+		return err
+	}
+	b.bc = bc
 	b.memory = data.M
 	b.steps = data.S
 	return nil
 }
 
 func NewBFSolution(source string, instructions, steps, memory int) (OutputChecker, error) {
-	bc, err := bf.Compile(source, instructions)
+	return NewBFSolutionDialect(source, instructions, steps, memory, bf.Dialect{})
+}
+
+// NewBFSolutionDialect is like [NewBFSolution], but the reference solution is executed using dialect d.
+func NewBFSolutionDialect(source string, instructions, steps, memory int, d bf.Dialect) (OutputChecker, error) {
+	bc, err := bf.CompileDialect(source, instructions, d)
 	return bfSolution{
 		bc:     bc,
 		steps:  steps,
@@ -113,20 +124,14 @@ func NewBFSolution(source string, instructions, steps, memory int) (OutputChecke
 }
 
 func (b bfSolution) CheckOutput(input string, output string) Verdict {
+	return checkAnswer(b, Comparison{}, input, output)
+}
+
+func (b bfSolution) answer(input string) (string, string, error) {
 	out := new(bytes.Buffer)
 	s := bf.NewState(b.bc, strings.NewReader(input), out, b.steps, b.memory)
 	if err := s.Run(); err != nil {
-		return Verdict{
-			Status:  StatusCheckerFailed,
-			Comment: err.Error(),
-		}
-	}
-
-	if out.String() != output {
-		return Verdict{
-			Status: StatusWrongAnswer,
-		}
+		return "", "", err
 	}
-
-	return Verdict{}
+	return out.String(), "", nil
 }
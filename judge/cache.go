@@ -0,0 +1,181 @@
+package judge
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"sync"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+// CacheKey identifies verdicts of a compiled submission on a problem. See [NewCacheKey].
+type CacheKey [sha256.Size]byte
+
+// NewCacheKey hashes a binary encoding of a problem (see [Problem.MarshalBinary]) together with a compiled submission.
+//
+// Byte code is hashed instead of the source, so submissions that only differ in comments share a key.
+// Any change of the problem encoding, including its limits and dialect, results in a different key.
+func NewCacheKey(problem []byte, bc bf.ByteCode) CacheKey {
+	h := sha256.New()
+	h.Write(binary.AppendUvarint(nil, uint64(len(problem))))
+	h.Write(problem)
+	code, _ := bc.MarshalBinary() // never fails
+	h.Write(code)
+
+	var key CacheKey
+	h.Sum(key[:0])
+	return key
+}
+
+// ResultCache stores verdicts of judged submissions. See [Judge.JudgeCached].
+//
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns verdicts stored under key, if any.
+	Get(key CacheKey) ([][]Verdict, bool)
+	// Put stores verdicts under key. Put may silently drop entries.
+	Put(key CacheKey, v [][]Verdict)
+}
+
+// JudgeCached is like [Judge.JudgeEarlyExit], but verdicts are looked up in cache first.
+// Returned hit reports whether verdicts were found in cache.
+//
+// Problem must be a binary encoding of p, preferably the one p was unmarshalled from,
+// because encoding is not guaranteed to be deterministic. Because problem is a part of the key,
+// changing a problem invalidates all of its cached verdicts.
+//
+// Only verdicts that are reproducible are stored: results with wall-clock time limits,
+// runtime errors (their comments point into the source), judge or checker failures
+// and results of a cancelled judging are always judged again.
+func (j Judge) JudgeCached(ctx context.Context, cache ResultCache, problem []byte, p Problem, submition string) (res [][]Verdict, hit bool) {
+	bc, err := bf.CompileDialect(submition, p.Instructions, p.Dialect)
+	if err != nil {
+		// not cached, compilation errors point into the source
+		return j.JudgeEarlyExit(ctx, p, submition), false
+	}
+
+	key := NewCacheKey(problem, bc)
+	if v, ok := cache.Get(key); ok {
+		return v, true
+	}
+
+	res = j.JudgeEarlyExit(ctx, p, submition)
+	if ctx.Err() == nil && cacheable(res) {
+		cache.Put(key, res)
+	}
+	return res, false
+}
+
+// cacheable reports whether verdicts do not depend on anything but the problem and the byte code.
+func cacheable(v [][]Verdict) bool {
+	for _, group := range v {
+		for _, v := range group {
+			switch v.Status {
+			case StatusAccept, StatusWrongAnswer, StatusPartial, StatusSkipped:
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// LRUCache is an in-memory [ResultCache] that holds a bounded number of entries
+// and evicts the least recently used one when full.
+//
+// Zero value cache is invalid, use [NewLRUCache] instead.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is the most recently used
+	entries  map[CacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key CacheKey
+	v   [][]Verdict
+}
+
+// NewLRUCache creates an empty cache that holds at most capacity entries.
+// At least one entry is always held.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[CacheKey]*list.Element),
+	}
+}
+
+// Get returns a copy of stored verdicts and marks the entry as recently used.
+func (c *LRUCache) Get(key CacheKey) ([][]Verdict, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return cloneVerdicts(e.Value.(*lruEntry).v), true
+}
+
+// Put stores a copy of v, evicting the least recently used entry if the cache is full.
+func (c *LRUCache) Put(key CacheKey, v [][]Verdict) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).v = cloneVerdicts(v)
+		c.order.MoveToFront(e)
+		return
+	}
+
+	for c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+		c.order.Remove(oldest)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key, cloneVerdicts(v)})
+}
+
+// Len returns the number of stored entries.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func cloneVerdicts(v [][]Verdict) [][]Verdict {
+	res := make([][]Verdict, len(v))
+	for i := range v {
+		res[i] = slices.Clone(v[i])
+	}
+	return res
+}
+
+// TieredCache combines caches ordered from the fastest to the slowest one.
+//
+// Get checks tiers in order and copies a found entry to all faster tiers.
+// Put stores an entry in all tiers.
+type TieredCache []ResultCache
+
+func (t TieredCache) Get(key CacheKey) ([][]Verdict, bool) {
+	for i, c := range t {
+		if v, ok := c.Get(key); ok {
+			for _, faster := range t[:i] {
+				faster.Put(key, v)
+			}
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (t TieredCache) Put(key CacheKey, v [][]Verdict) {
+	for _, c := range t {
+		c.Put(key, v)
+	}
+}
@@ -0,0 +1,138 @@
+package judge_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+// countingGenerator counts how many times tests were generated.
+type countingGenerator struct {
+	judge.InputGenerator
+	calls *int
+}
+
+func (g countingGenerator) GenerateInput() ([][]string, error) {
+	*g.calls++
+	return g.InputGenerator.GenerateInput()
+}
+
+func TestJudgeCached(t *testing.T) {
+	calls := 0
+	p := judge.Problem{
+		InputGenerator: countingGenerator{judge.NewListGenerator([][]string{{"a", "b"}, {"c"}}), &calls},
+		OutputChecker: judge.NewListSolutionSlice(
+			judge.Pair{Input: "a", Output: "a"},
+			judge.Pair{Input: "b", Output: "b"},
+			judge.Pair{Input: "c", Output: "c"},
+		),
+		Steps:  1000,
+		Memory: 100,
+	}
+
+	j := judge.NewJudge(2)
+	defer j.Close()
+	cache := judge.NewLRUCache(10)
+	ctx := context.Background()
+	blob := []byte("problem v1")
+
+	first, hit := j.JudgeCached(ctx, cache, blob, p, ",.")
+	if hit || calls != 1 {
+		t.Fatalf("first judging: hit = %v, calls = %d", hit, calls)
+	}
+	if score := judge.CalculateScore(first); score != 1 {
+		t.Fatalf("score = %v, verdicts = %v", score, first)
+	}
+
+	// comments do not change byte code
+	second, hit := j.JudgeCached(ctx, cache, blob, p, "read, write. done")
+	if !hit || calls != 1 {
+		t.Errorf("same byte code: hit = %v, calls = %d", hit, calls)
+	}
+	if second[0][0].Status != first[0][0].Status || len(second) != len(first) {
+		t.Errorf("cached verdicts = %v, want %v", second, first)
+	}
+
+	// changed problem invalidates the entry
+	if _, hit := j.JudgeCached(ctx, cache, []byte("problem v2"), p, ",."); hit || calls != 2 {
+		t.Errorf("changed problem: hit = %v, calls = %d", hit, calls)
+	}
+
+	// wrong answers are cached too
+	j.JudgeCached(ctx, cache, blob, p, ",+.")
+	if _, hit := j.JudgeCached(ctx, cache, blob, p, ",+."); !hit {
+		t.Error("wrong answer was not cached")
+	}
+
+	// runtime errors point into the source, so they are judged again
+	j.JudgeCached(ctx, cache, blob, p, ",,,.")
+	if _, hit := j.JudgeCached(ctx, cache, blob, p, ",,,."); hit {
+		t.Error("runtime error was cached")
+	}
+
+	// compilation errors are never cached
+	if v, hit := j.JudgeCached(ctx, cache, blob, p, "[,."); hit || v[0][0].Status != judge.StatusCompilationFailed {
+		t.Errorf("compilation error: hit = %v, verdicts = %v", hit, v)
+	}
+}
+
+func TestNewCacheKey(t *testing.T) {
+	compile := func(source string, d bf.Dialect) bf.ByteCode {
+		bc, err := bf.CompileDialect(source, -1, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bc
+	}
+
+	key := judge.NewCacheKey([]byte("p"), compile(",[.,]", bf.Dialect{}))
+	if judge.NewCacheKey([]byte("p"), compile("loop: ,[.,] end", bf.Dialect{})) != key {
+		t.Error("comments changed the key")
+	}
+	if judge.NewCacheKey([]byte("q"), compile(",[.,]", bf.Dialect{})) == key {
+		t.Error("problem did not change the key")
+	}
+	if judge.NewCacheKey([]byte("p"), compile(",[..,]", bf.Dialect{})) == key {
+		t.Error("program did not change the key")
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := judge.NewLRUCache(2)
+	a, b, d := judge.CacheKey{1}, judge.CacheKey{2}, judge.CacheKey{3}
+	v := [][]judge.Verdict{{{Status: judge.StatusAccept}}}
+
+	c.Put(a, v)
+	c.Put(b, v)
+	c.Get(a) // b is now the least recently used
+	c.Put(d, v)
+
+	if _, ok := c.Get(b); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get(a); !ok {
+		t.Error("recently used entry was evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("len = %d, want 2", c.Len())
+	}
+
+	// stored verdicts are copied
+	got, _ := c.Get(d)
+	got[0][0].Status = judge.StatusWrongAnswer
+	if got, _ := c.Get(d); got[0][0].Status != judge.StatusAccept {
+		t.Error("cached entry was modified through a returned slice")
+	}
+
+	slow := judge.NewLRUCache(10)
+	slow.Put(b, v)
+	tiered := judge.TieredCache{c, slow}
+	if _, ok := tiered.Get(b); !ok {
+		t.Fatal("entry of the slower tier was not found")
+	}
+	if _, ok := c.Get(b); !ok {
+		t.Error("entry was not copied to the faster tier")
+	}
+}
@@ -0,0 +1,210 @@
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Comparison decides how submission output is compared with a reference answer.
+// It is honored by all checkers that have a reference answer:
+// [NewBFSolution], [NewListSolution] and lua checkers in solution mode.
+//
+// Zero value requires outputs to be byte for byte equal.
+type Comparison struct {
+	TrailingSpace bool    // Ignore trailing whitespace of every line and trailing empty lines.
+	Tokens        bool    // Compare whitespace separated tokens, ignoring any whitespace differences.
+	IgnoreCase    bool    // Compare letters case insensitively.
+	LineEndings   bool    // Treat "\r\n" line endings as "\n".
+	Epsilon       float64 // If positive, numeric tokens may differ by Epsilon, absolute or relative. Implies Tokens.
+}
+
+// referenceChecker is implemented by checkers that compare output with a reference answer.
+type referenceChecker interface {
+	// answer returns expected output for input and a comment shown if output differs from it.
+	// Error of type errNoAnswer means that checker does not use reference answers.
+	answer(input string) (want, comment string, err error)
+}
+
+var errNoAnswer = errors.New("checker does not have a reference answer")
+
+// CheckOutput checks output using [Problem.OutputChecker].
+// Output of checkers with a reference answer is compared according to [Problem.Comparison].
+func (p Problem) CheckOutput(input string, output string) Verdict {
+	return checkAnswer(p.OutputChecker, p.Comparison, input, output)
+}
+
+func checkAnswer(c OutputChecker, cmp Comparison, input, output string) Verdict {
+	r, ok := c.(referenceChecker)
+	if !ok {
+		return c.CheckOutput(input, output)
+	}
+
+	want, comment, err := r.answer(input)
+	if errors.Is(err, errNoAnswer) {
+		return c.CheckOutput(input, output)
+	}
+	if err != nil {
+		return Verdict{
+			Status:  StatusCheckerFailed,
+			Comment: err.Error(),
+		}
+	}
+
+	if diff := cmp.Diff(want, output); diff != "" {
+		return Verdict{
+			Status:  StatusWrongAnswer,
+			Comment: comment,
+			Detail:  diff,
+		}
+	}
+
+	return Verdict{}
+}
+
+// Diff compares output got with expected output want.
+// Returns empty string if they are equal, a short description of the first difference otherwise.
+func (c Comparison) Diff(want, got string) string {
+	if c.LineEndings {
+		want = strings.ReplaceAll(want, "\r\n", "\n")
+		got = strings.ReplaceAll(got, "\r\n", "\n")
+	}
+
+	if c.Tokens || c.Epsilon > 0 {
+		return c.diffTokens(strings.Fields(want), strings.Fields(got))
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	if c.TrailingSpace {
+		wantLines, gotLines = trimLines(wantLines), trimLines(gotLines)
+	}
+
+	for i := range min(len(wantLines), len(gotLines)) {
+		if !c.equal(wantLines[i], gotLines[i]) {
+			return fmt.Sprintf("line %d: expected %q, found %q", i+1, shorten(wantLines[i]), shorten(gotLines[i]))
+		}
+	}
+	if len(wantLines) > len(gotLines) {
+		return fmt.Sprintf("line %d: expected %q, found end of output", len(gotLines)+1, shorten(wantLines[len(gotLines)]))
+	}
+	if len(wantLines) < len(gotLines) {
+		return fmt.Sprintf("line %d: expected end of output, found %q", len(wantLines)+1, shorten(gotLines[len(wantLines)]))
+	}
+	return ""
+}
+
+func (c Comparison) diffTokens(want, got []string) string {
+	for i := range min(len(want), len(got)) {
+		if !c.equal(want[i], got[i]) && !c.close(want[i], got[i]) {
+			return fmt.Sprintf("token %d: expected %q, found %q", i+1, shorten(want[i]), shorten(got[i]))
+		}
+	}
+	if len(want) != len(got) {
+		return fmt.Sprintf("expected %d tokens, found %d", len(want), len(got))
+	}
+	return ""
+}
+
+func (c Comparison) equal(want, got string) bool {
+	if c.IgnoreCase {
+		return strings.EqualFold(want, got)
+	}
+	return want == got
+}
+
+// close reports whether both tokens are numbers within [Comparison.Epsilon] of each other.
+func (c Comparison) close(want, got string) bool {
+	if c.Epsilon <= 0 {
+		return false
+	}
+	a, err := strconv.ParseFloat(want, 64)
+	if err != nil {
+		return false
+	}
+	b, err := strconv.ParseFloat(got, 64)
+	if err != nil || math.IsNaN(b) {
+		return false
+	}
+	return math.Abs(a-b) <= c.Epsilon*max(1, math.Abs(a))
+}
+
+// trimLines removes trailing whitespace of every line and trailing empty lines.
+func trimLines(lines []string) []string {
+	res := make([]string, len(lines))
+	for i, line := range lines {
+		res[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(res) > 0 && res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+// shorten truncates long lines and tokens in comments.
+func shorten(s string) string {
+	const limit = 32
+	if len(s) > limit {
+		return s[:limit] + "..."
+	}
+	return s
+}
+
+// String formats comparison in a format accepted by [ParseComparison].
+func (c Comparison) String() string {
+	var fields []string
+	if c.TrailingSpace {
+		fields = append(fields, "trailing-space")
+	}
+	if c.Tokens {
+		fields = append(fields, "tokens")
+	}
+	if c.IgnoreCase {
+		fields = append(fields, "ignore-case")
+	}
+	if c.LineEndings {
+		fields = append(fields, "line-endings")
+	}
+	if c.Epsilon > 0 {
+		fields = append(fields, "epsilon="+strconv.FormatFloat(c.Epsilon, 'g', -1, 64))
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseComparison parses a whitespace separated list of comparison options:
+// "trailing-space", "tokens", "ignore-case", "line-endings" and "epsilon=X".
+// Empty string and "exact" are the exact comparison.
+func ParseComparison(s string) (Comparison, error) {
+	var res Comparison
+	for _, f := range strings.Fields(s) {
+		key, value, hasValue := strings.Cut(f, "=")
+		if hasValue != (key == "epsilon") {
+			return Comparison{}, fmt.Errorf("invalid comparison option %q", f)
+		}
+
+		switch key {
+		case "exact":
+		case "trailing-space":
+			res.TrailingSpace = true
+		case "tokens":
+			res.Tokens = true
+		case "ignore-case":
+			res.IgnoreCase = true
+		case "line-endings":
+			res.LineEndings = true
+		case "epsilon":
+			eps, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return Comparison{}, fmt.Errorf("epsilon %q: %w", value, err)
+			}
+			if !(eps > 0) || math.IsInf(eps, 0) {
+				return Comparison{}, fmt.Errorf("epsilon %q must be a positive number", value)
+			}
+			res.Epsilon = eps
+		default:
+			return Comparison{}, fmt.Errorf("unknown comparison option %q", f)
+		}
+	}
+	return res, nil
+}
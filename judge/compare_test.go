@@ -0,0 +1,149 @@
+package judge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+func TestComparison(t *testing.T) {
+	tests := []struct {
+		compare   string
+		want, got string
+		equal     bool
+	}{
+		{"", "5\n", "5\n", true},
+		{"", "5\n", "5", false},
+		{"exact", "5", "5 ", false},
+		{"trailing-space", "5\n", "5", true},
+		{"trailing-space", "1 2\n3\n", "1 2  \n3\n\n\n", true},
+		{"trailing-space", "1 2\n3\n", "1  2\n3\n", false},
+		{"trailing-space", "1\n2\n", "1\n", false},
+		{"tokens", "1 2\n3\n", " 1\n2   3", true},
+		{"tokens", "1 2 3", "1 2", false},
+		{"ignore-case", "YES\n", "yes\n", true},
+		{"ignore-case", "YES\n", "no\n", false},
+		{"line-endings", "a\nb\n", "a\r\nb\r\n", true},
+		{"", "a\nb\n", "a\r\nb\r\n", false},
+		{"epsilon=1e-6", "0.333333", "0.3333331", true},
+		{"epsilon=1e-6", "1000000", "1000000.5", true},
+		{"epsilon=1e-6", "0.333333", "0.3334", false},
+		{"epsilon=1e-6", "1 word", "1.0000000001 word", true},
+		{"epsilon=1e-6", "1 word", "1 Word", false},
+		{"epsilon=1e-6 ignore-case", "1 word", "1 Word", true},
+	}
+
+	for _, tt := range tests {
+		c, err := judge.ParseComparison(tt.compare)
+		if err != nil {
+			t.Fatalf("%q: err = %v", tt.compare, err)
+		}
+
+		diff := c.Diff(tt.want, tt.got)
+		if (diff == "") != tt.equal {
+			t.Errorf("%q: Diff(%q, %q) = %q, want equal %v", tt.compare, tt.want, tt.got, diff, tt.equal)
+		}
+
+		again, err := judge.ParseComparison(c.String())
+		if err != nil || again != c {
+			t.Errorf("%q: round trip got %q (err = %v) want %q", tt.compare, again, err, c)
+		}
+	}
+}
+
+func TestParseComparisonErrors(t *testing.T) {
+	for _, s := range []string{"fuzzy", "epsilon", "epsilon=0", "epsilon=-1", "epsilon=x", "tokens=1"} {
+		if _, err := judge.ParseComparison(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestDocumentCompare(t *testing.T) {
+	const data = `
+.task = Echo
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.dialect = eof=zero
+.compare = trailing-space
+
+.lua
+function solution(input)
+	return input .. "\n"
+end
+
+test_data = {
+	{"hello", "a"},
+}
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// comparison must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if p.Comparison != (judge.Comparison{TrailingSpace: true}) {
+		t.Fatalf("comparison = %v want trailing-space", p.Comparison)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	// echoes input without a trailing newline
+	for gi, group := range j.Judge(p, `,[.,]`) {
+		for ti, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("group %d test %d: %v (%v)", gi, ti, v.Status, v.Comment)
+			}
+		}
+	}
+
+	p.Comparison = judge.Comparison{}
+	v := p.CheckOutput("hello", "hello")
+	if v.Status != judge.StatusWrongAnswer || v.Detail != `line 2: expected "", found end of output` {
+		t.Errorf("exact comparison: %v (%v)", v.Status, v.Detail)
+	}
+	if v.Comment != "" {
+		t.Errorf("comment %q reveals the answer", v.Comment)
+	}
+}
+
+func TestNumericLuaSolution(t *testing.T) {
+	c, err := judge.NewLuaChecker(`
+		function solution(input)
+			local a, b = input:match("(%d+) (%d+)")
+			print("sum")
+			return a + b
+		end
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := judge.Problem{OutputChecker: c}
+
+	if v := p.CheckOutput("2 3", "5"); v.Status != judge.StatusAccept {
+		t.Errorf("correct output: got %v want Accept", v.Error())
+	}
+	v := p.CheckOutput("2 3", "6")
+	if v.Status != judge.StatusWrongAnswer || v.Comment != "sum\n" {
+		t.Errorf("wrong output: got %v (%q) want WrongAnswer with the printed comment", v.Status, v.Comment)
+	}
+}
@@ -1,10 +1,14 @@
 package judge_test
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/bf"
 	"github.com/TrueHopolok/braincode-/judge/ml"
 )
 
@@ -122,6 +126,115 @@ test_data = {
 
 }
 
+func TestDocumentTime(t *testing.T) {
+	const data = `
+.task = Cat
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.time = %d
+
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {{"a"}}
+..
+`
+
+	for limit, want := range map[int]time.Duration{
+		0:      judge.DefaultTimeLimit,
+		500:    500 * time.Millisecond,
+		60_000: time.Minute,
+		60_001: 0,
+		-1:     0,
+	} {
+		doc, err := ml.Parse(strings.NewReader(fmt.Sprintf(data, limit)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := judge.NewProblem(doc)
+		if want == 0 {
+			if err == nil {
+				t.Errorf("time %d: expected an error", limit)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("time %d: %v", limit, err)
+		}
+
+		// time limit must survive serialization
+		b, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if p.Time != want {
+			t.Errorf("time %d: got %v want %v", limit, p.Time, want)
+		}
+	}
+}
+
+func TestDocumentDialect(t *testing.T) {
+	const data = `
+.task = Cat
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.dialect = eof=unchanged
+
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {
+	{"hello", "a", ""},
+}
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dialect must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if p.Dialect != (bf.Dialect{EOF: bf.EOFUnchanged}) {
+		t.Fatalf("dialect = %v want eof=unchanged", p.Dialect)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	// relies on EOF leaving the zeroed cell unchanged
+	for gi, group := range j.Judge(p, `,[.[-],]`) {
+		for ti, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("group %d test %d: %v (%v)", gi, ti, v.Status, v.Comment)
+			}
+		}
+	}
+}
+
 func TestParseError(t *testing.T) {
 	const data = "" +
 		`.task = A + B
@@ -194,3 +307,217 @@ test_data = {
 	}
 	t.Log(err)
 }
+
+func TestDocumentGenerate(t *testing.T) {
+	const data = `
+.task = Cat
+
+.steps = 100000
+.instructions = 100
+.memory = 200
+.dialect = eof=zero
+
+.generate
+tests=3 seed=1 n=5
+tests=2 seed=50 n=100
+..
+
+.lua
+function test_data(seed, params)
+	local res = ""
+	for i = 1, params.n do
+		res = res .. string.char(random(65, 90))
+	end
+	return res
+end
+
+function solution(input)
+	return input
+end
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// groups must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	tests, err := p.GenerateInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 || len(tests[0]) != 3 || len(tests[1]) != 2 || len(tests[1][1]) != 100 {
+		t.Fatalf("got %q", tests)
+	}
+
+	if seed, ok := judge.TestSeed(p.InputGenerator, 1, 1); !ok || seed != 51 {
+		t.Errorf("TestSeed = %v, %v want 51, true", seed, ok)
+	}
+	if _, ok := judge.TestSeed(p.InputGenerator, 2, 0); ok {
+		t.Error("TestSeed of a missing group is ok")
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	for gi, group := range j.Judge(p, `,[.,]`) {
+		for ti, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("group %d test %d: %v (%v)", gi, ti, v.Status, v.Comment)
+			}
+		}
+	}
+}
+
+func TestDocumentStaticTests(t *testing.T) {
+	const data = `
+.task = Double
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.examples = tests
+
+.paragraph = Output the input byte twice.
+.example
+.input = a
+.output = aa
+..
+
+.test
+.input = b
+.output = bb
+..
+.group
+.test
+.input = c
+.output = cc
+..
+.test
+.input = d
+.output = dd
+..
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// static tests must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	tests, err := p.GenerateInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 3 || tests[0][0] != "a" || tests[1][0] != "b" || len(tests[2]) != 2 {
+		t.Fatalf("got %q", tests)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	for gi, group := range j.Judge(p, `,..`) {
+		for ti, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("group %d test %d: %v (%v)", gi, ti, v.Status, v.Comment)
+			}
+		}
+	}
+	if v := j.Judge(p, `,.`)[2][1]; v.Status != judge.StatusWrongAnswer {
+		t.Errorf("got %v, want WrongAnswer", v.Status)
+	}
+
+	doc.Lua = "function solution(input) return input end test_data = {{\"a\"}}"
+	if _, err := judge.NewProblem(doc); err == nil {
+		t.Error("static tests combined with a lua generator")
+	}
+}
+
+func TestDocumentExampleTestsWithGenerator(t *testing.T) {
+	const data = `
+.task = Double
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.examples = tests
+
+.paragraph = Output the input byte twice.
+.example
+.input = a
+.output = aa
+..
+.example
+.input = b
+.output = bb
+..
+
+.lua
+function solution(input)
+	return input .. input
+end
+
+test_data = {{"c", "d"}, {"e"}}
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests, err := p.GenerateInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	if !reflect.DeepEqual(tests, want) {
+		t.Fatalf("got %q want %q", tests, want)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	for gi, group := range j.Judge(p, `,..`) {
+		for ti, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("group %d test %d: %v (%v)", gi, ti, v.Status, v.Comment)
+			}
+		}
+	}
+	// examples are judged by the lua solution
+	if v := j.Judge(p, `,.`)[0][0]; v.Status != judge.StatusWrongAnswer {
+		t.Errorf("got %v, want WrongAnswer", v.Status)
+	}
+}
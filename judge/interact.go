@@ -0,0 +1,110 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+// Interactor decides verdicts of an interactive problem by talking to a submission.
+// If [Problem.Interactor] is set, [Problem.OutputChecker] is not used.
+type Interactor interface {
+	// Interact reads submission output from r and writes submission input to w.
+	// Test input is not sent to the submission automatically.
+	//
+	// Interact must return once ctx is done or r and w are closed.
+	Interact(ctx context.Context, input string, r io.Reader, w io.Writer) Verdict
+}
+
+type luaInteractor struct{ lua.Interactor }
+
+// NewLuaInteractor creates a new lua interactor.
+// See [lua.NewInteractor] for details.
+func NewLuaInteractor(source string) (Interactor, error) {
+	it, err := lua.NewInteractor(source)
+	return &luaInteractor{it}, err
+}
+
+func (l *luaInteractor) Interact(ctx context.Context, input string, r io.Reader, w io.Writer) Verdict {
+	return scoreVerdict(l.Interactor.Interact(ctx, input, r, w))
+}
+
+// errSubmissionFinished is returned to the interactor, when it writes to a terminated submission.
+var errSubmissionFinished = errors.New("submission has terminated")
+
+// judgeInteractive runs submission and interactor concurrently, connecting them with pipes.
+//
+// Runtime errors of the submission take precedence over the interactor verdict.
+// The only exception is reading past the end of input after the interactor rejected the submission,
+// then the interactor verdict is reported, because it is more precise.
+// Reading past the end of input after the interactor accepted the submission is [StatusInputExhausted].
+func judgeInteractive(ctx context.Context, j job) Verdict {
+	inR, inW := io.Pipe()   // interactor -> submission
+	outR, outW := io.Pipe() // submission -> interactor
+
+	// blocking reads and writes are not interrupted by ctx otherwise
+	stop := context.AfterFunc(ctx, func() {
+		err := context.Cause(ctx)
+		inR.CloseWithError(err)
+		inW.CloseWithError(err)
+		outR.CloseWithError(err)
+		outW.CloseWithError(err)
+	})
+	defer stop()
+
+	out := &countingWriter{w: outW}
+	s := bf.NewState(j.bc, inR, out, j.steps, j.memory)
+
+	start := time.Now()
+
+	done := make(chan error, 1)
+	go func() {
+		err := s.RunContext(ctx)
+		outW.Close()
+		inR.CloseWithError(errSubmissionFinished)
+		done <- err
+	}()
+
+	v := j.interactor.Interact(ctx, j.input, outR, inW)
+	inW.Close()
+
+	// output after the interaction ended is ignored
+	go io.Copy(io.Discard, outR)
+
+	err := <-done
+	elapsed := time.Since(start)
+
+	if j.ctx.Err() != nil {
+		return cancelledVerdict(j.ctx)
+	}
+	if ctx.Err() != nil {
+		// closed pipes may have failed the submission before it noticed the deadline
+		err = ctx.Err()
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, io.EOF):
+		v = runtimeErrorVerdict(j.bc, &s, err)
+	case v.Status != StatusAccept && v.Status != StatusPartial:
+		// interactor verdict is more precise than reading past the end of input
+	case err != nil:
+		v = runtimeErrorVerdict(j.bc, &s, err)
+	}
+
+	return withUsage(v, j, &s, out.n, elapsed)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += n
+	return n, err
+}
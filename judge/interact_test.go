@@ -0,0 +1,70 @@
+package judge_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TrueHopolok/braincode-/judge"
+)
+
+func TestInteractive(t *testing.T) {
+	const interactor = `
+	function interactor(input)
+		for i = 1, #input do
+			write(input:sub(i, i))
+			if read() ~= string.char(input:byte(i) + 1) then
+				return "wrong reply"
+			end
+		end
+		write(string.char(0))
+	end
+	`
+
+	it, err := judge.NewLuaInteractor(interactor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"abc", "\x01"}}),
+		Interactor:     it,
+		Steps:          1000,
+		Memory:         10,
+		Time:           500 * time.Millisecond,
+	}
+
+	// interactor must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	J := judge.NewJudge(2)
+	defer J.Close()
+
+	tests := []struct {
+		submition string
+		status    judge.Status
+	}{
+		{",[+.,]", judge.StatusAccept},
+		// reads after the interactor rejected, the rejection is reported
+		{",[.,]", judge.StatusWrongAnswer},
+		// reads after the interactor accepted
+		{",[+.,],", judge.StatusInputExhausted},
+		{",[+.,]+.,", judge.StatusInputExhausted},
+		{"+[]", judge.StatusTimeLimit},
+		{",[+.>,]", judge.StatusAccept},
+		{",[+.>>>>>>>>>>>>,]", judge.StatusMemoryLimit},
+	}
+
+	for _, tt := range tests {
+		for _, v := range J.Judge(p, tt.submition)[0] {
+			if v.Status != tt.status {
+				t.Errorf("%q: got %v want %v", tt.submition, v, tt.status)
+			}
+		}
+	}
+}
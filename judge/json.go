@@ -0,0 +1,291 @@
+package judge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+// problemJSON is a human readable encoding of a problem, see [Problem.MarshalJSON].
+type problemJSON struct {
+	Instructions int    `json:"instructions"`
+	Steps        int    `json:"steps"`
+	Memory       int    `json:"memory"`
+	Time         string `json:"time,omitempty"`
+	Dialect      string `json:"dialect,omitempty"`
+	Scoring      string `json:"scoring,omitempty"`
+	Comparison   string `json:"comparison,omitempty"`
+
+	Generator  *componentJSON `json:"generator"`
+	Checker    *componentJSON `json:"checker,omitempty"`
+	Interactor *componentJSON `json:"interactor,omitempty"`
+	Validator  *componentJSON `json:"validator,omitempty"`
+}
+
+// componentJSON is a generator, checker, interactor or validator.
+// Kind is a section kind without the role, such as "lua" for "checker/lua". See [Problem.AppendBinary].
+type componentJSON struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source,omitempty"` // lua source or brainfunk byte code
+
+	Tests   [][]string   `json:"tests,omitempty"`   // list generator
+	Groups  []string     `json:"groups,omitempty"`  // seeded lua generator, see [lua.GroupSpec]
+	Answers []answerJSON `json:"answers,omitempty"` // list solution, sorted by input
+
+	// Brainfunk solution.
+	Steps   int    `json:"steps,omitempty"`
+	Memory  int    `json:"memory,omitempty"`
+	Dialect string `json:"dialect,omitempty"`
+}
+
+type answerJSON struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// kind returns a section kind without its role.
+func kind(section string) string {
+	_, k, _ := strings.Cut(section, "/")
+	return k
+}
+
+// MarshalJSON encodes a problem as a human readable JSON object, that can be diffed and edited by hand.
+// It contains the same information as [Problem.MarshalBinary], brainfunk programs are stored as byte code text.
+//
+// Implements [json.Marshaler].
+func (p Problem) MarshalJSON() ([]byte, error) {
+	res := problemJSON{
+		Instructions: p.Instructions,
+		Steps:        p.Steps,
+		Memory:       p.Memory,
+		Dialect:      p.Dialect.String(),
+		Scoring:      p.Scoring.String(),
+		Comparison:   p.Comparison.String(),
+	}
+	if p.Time > 0 {
+		res.Time = p.Time.String()
+	}
+
+	var err error
+	if res.Generator, err = generatorJSON(p.InputGenerator); err != nil {
+		return nil, err
+	}
+	if p.Interactor != nil {
+		res.Interactor, err = interactorJSON(p.Interactor)
+	} else {
+		res.Checker, err = checkerJSON(p.OutputChecker)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Validator != nil {
+		if res.Validator, err = validatorJSON(p.Validator); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON decodes a problem encoded by [Problem.MarshalJSON].
+// Unknown fields are rejected, so that typos in hand written problems do not go unnoticed.
+//
+// Implements [json.Unmarshaler].
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var val problemJSON
+	if err := dec.Decode(&val); err != nil {
+		return err
+	}
+
+	res := Problem{
+		Instructions: val.Instructions,
+		Steps:        val.Steps,
+		Memory:       val.Memory,
+	}
+
+	var err error
+	if val.Time != "" {
+		if res.Time, err = time.ParseDuration(val.Time); err != nil {
+			return fmt.Errorf("time: %w", err)
+		}
+	}
+	if res.Dialect, err = bf.ParseDialect(val.Dialect); err != nil {
+		return fmt.Errorf("dialect: %w", err)
+	}
+	if res.Scoring, err = ParseScoring(val.Scoring); err != nil {
+		return fmt.Errorf("scoring: %w", err)
+	}
+	if res.Comparison, err = ParseComparison(val.Comparison); err != nil {
+		return fmt.Errorf("comparison: %w", err)
+	}
+
+	if val.Generator == nil {
+		return errors.New("problem does not have a generator")
+	}
+	if res.InputGenerator, err = val.Generator.generator(); err != nil {
+		return fmt.Errorf("generator: %w", err)
+	}
+
+	switch {
+	case val.Checker != nil && val.Interactor != nil:
+		return errors.New("problem has both a checker and an interactor")
+	case val.Checker != nil:
+		if res.OutputChecker, err = val.Checker.checker(); err != nil {
+			return fmt.Errorf("checker: %w", err)
+		}
+	case val.Interactor != nil:
+		if res.Interactor, err = val.Interactor.interactor(); err != nil {
+			return fmt.Errorf("interactor: %w", err)
+		}
+	default:
+		return errors.New("problem does not have a checker or an interactor")
+	}
+
+	if val.Validator != nil {
+		if res.Validator, err = val.Validator.validator(); err != nil {
+			return fmt.Errorf("validator: %w", err)
+		}
+	}
+
+	*p = res
+	return nil
+}
+
+func generatorJSON(gen InputGenerator) (*componentJSON, error) {
+	switch g := gen.(type) {
+	case listGenerator:
+		return &componentJSON{Kind: kind(sectionListGenerator), Tests: g}, nil
+	case bfGenerator:
+		return &componentJSON{Kind: kind(sectionBFGenerator), Source: bf.ByteCode(g).String()}, nil
+	case luaGenerator:
+		return &componentJSON{Kind: kind(sectionLuaGenerator), Source: string(g)}, nil
+	case seededLuaGenerator:
+		res := &componentJSON{Kind: kind(sectionSeededLuaGenerator), Source: g.Source}
+		for _, spec := range g.Groups {
+			res.Groups = append(res.Groups, spec.String())
+		}
+		return res, nil
+	case *smartGenerator:
+		return generatorJSON(g.InputGenerator)
+	default:
+		return nil, fmt.Errorf("unexpected generator: %T", g)
+	}
+}
+
+func (c *componentJSON) generator() (InputGenerator, error) {
+	switch "generator/" + c.Kind {
+	case sectionListGenerator:
+		return listGenerator(c.Tests), nil
+	case sectionBFGenerator:
+		return NewBFGenerator(c.Source)
+	case sectionLuaGenerator:
+		return NewLuaGenerator(c.Source), nil
+	case sectionSeededLuaGenerator:
+		groups, err := lua.ParseGroupSpecs(strings.Join(c.Groups, "\n"))
+		if err != nil {
+			return nil, err
+		}
+		return NewSeededLuaGenerator(c.Source, groups), nil
+	default:
+		return nil, fmt.Errorf("unknown kind %q", c.Kind)
+	}
+}
+
+func checkerJSON(checker OutputChecker) (*componentJSON, error) {
+	switch c := checker.(type) {
+	case listSolution:
+		res := &componentJSON{Kind: kind(sectionListSolution)}
+		for _, input := range slices.Sorted(maps.Keys(c)) {
+			res.Answers = append(res.Answers, answerJSON{input, c[input]})
+		}
+		return res, nil
+	case bfSolution:
+		return &componentJSON{
+			Kind:    kind(sectionBFSolution),
+			Source:  c.bc.String(),
+			Steps:   c.steps,
+			Memory:  c.memory,
+			Dialect: c.bc.Dialect().String(),
+		}, nil
+	case bfChecker:
+		return &componentJSON{Kind: kind(sectionBFChecker), Source: bf.ByteCode(c).String()}, nil
+	case *luaChecker:
+		return &componentJSON{Kind: kind(sectionLuaChecker), Source: c.Source()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected checker: %T", c)
+	}
+}
+
+func (c *componentJSON) checker() (OutputChecker, error) {
+	switch "checker/" + c.Kind {
+	case sectionListSolution:
+		answers := make(listSolution, len(c.Answers))
+		for _, a := range c.Answers {
+			answers[a.Input] = a.Output
+		}
+		return answers, nil
+	case sectionBFSolution:
+		d, err := bf.ParseDialect(c.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		return NewBFSolutionDialect(c.Source, -1, c.Steps, c.Memory, d)
+	case sectionBFChecker:
+		return NewBFChecker(c.Source)
+	case sectionLuaChecker:
+		return NewLuaChecker(c.Source)
+	default:
+		return nil, fmt.Errorf("unknown kind %q", c.Kind)
+	}
+}
+
+func interactorJSON(interactor Interactor) (*componentJSON, error) {
+	switch it := interactor.(type) {
+	case *luaInteractor:
+		return &componentJSON{Kind: kind(sectionLuaInteractor), Source: it.Source()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected interactor: %T", it)
+	}
+}
+
+func (c *componentJSON) interactor() (Interactor, error) {
+	switch "interactor/" + c.Kind {
+	case sectionLuaInteractor:
+		return NewLuaInteractor(c.Source)
+	default:
+		return nil, fmt.Errorf("unknown kind %q", c.Kind)
+	}
+}
+
+func validatorJSON(validator InputValidator) (*componentJSON, error) {
+	switch v := validator.(type) {
+	case bfValidator:
+		return &componentJSON{Kind: kind(sectionBFValidator), Source: bf.ByteCode(v).String()}, nil
+	case *luaValidator:
+		return &componentJSON{Kind: kind(sectionLuaValidator), Source: v.Source()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected validator: %T", v)
+	}
+}
+
+func (c *componentJSON) validator() (InputValidator, error) {
+	switch "validator/" + c.Kind {
+	case sectionBFValidator:
+		return NewBFValidator(c.Source)
+	case sectionLuaValidator:
+		return NewLuaValidator(c.Source)
+	default:
+		return nil, fmt.Errorf("unknown kind %q", c.Kind)
+	}
+}
@@ -0,0 +1,118 @@
+package judge_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+func TestProblemJSONRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "problem_*.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no problems in testdata")
+	}
+
+	checker, err := judge.NewBFChecker(",[.,]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen, err := judge.NewBFGenerator("+++.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handmade, err := (&judge.Problem{InputGenerator: gen, OutputChecker: checker, Steps: 10}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blobs := map[string][]byte{"bf checker": handmade}
+	for _, file := range files {
+		legacy, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blobs[file], _, err = judge.UpgradeProblem(legacy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, blob := range blobs {
+		var p judge.Problem
+		if err := p.UnmarshalBinary(blob); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		text, err := json.MarshalIndent(p, "", "\t")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		var got judge.Problem
+		if err := json.Unmarshal(text, &got); err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, text)
+		}
+
+		b, err := got.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(b, blob) {
+			t.Errorf("%s: binary form changed after a JSON round trip:\n%s", name, text)
+		}
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	const text = `{
+	"instructions": 100,
+	"steps": 1000,
+	"memory": 50,
+	"time": "1.5s",
+	"dialect": "eof=zero",
+	"scoring": "sum 2 3",
+	"generator": {"kind": "list", "tests": [["a", "b"], ["c"]]},
+	"checker": {"kind": "list", "answers": [
+		{"input": "a", "output": "a"},
+		{"input": "b", "output": "b"},
+		{"input": "c", "output": "c"}
+	]}
+}`
+
+	var p judge.Problem
+	if err := json.Unmarshal([]byte(text), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Instructions != 100 || p.Steps != 1000 || p.Memory != 50 || p.Time.Seconds() != 1.5 ||
+		p.Dialect != (bf.Dialect{EOF: bf.EOFZero}) || p.Scoring.String() != "sum 2 3" {
+		t.Errorf("problem = %+v", p)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+	if v := j.Judge(p, ",[.,]"); judge.CalculateScore(v) != 1 {
+		t.Errorf("verdicts = %v", v)
+	}
+
+	invalid := map[string]string{
+		"unknown field":        `{"generator": {"kind": "list"}, "checker": {"kind": "list"}, "stpes": 1}`,
+		"unknown kind":         `{"generator": {"kind": "python"}, "checker": {"kind": "list"}}`,
+		"no generator":         `{"checker": {"kind": "list"}}`,
+		"no checker":           `{"generator": {"kind": "list"}}`,
+		"checker and interact": `{"generator": {"kind": "list"}, "checker": {"kind": "list"}, "interactor": {"kind": "lua"}}`,
+		"bad time":             `{"time": "soon", "generator": {"kind": "list"}, "checker": {"kind": "list"}}`,
+		"bad program":          `{"generator": {"kind": "list"}, "checker": {"kind": "bf", "source": "[["}}`,
+	}
+	for name, text := range invalid {
+		if err := json.Unmarshal([]byte(text), &p); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
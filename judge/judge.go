@@ -2,9 +2,14 @@ package judge
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
 	"math"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/TrueHopolok/braincode-/judge/bf"
 )
@@ -45,46 +50,45 @@ func (j Judge) Close() error {
 
 type job struct {
 	OutputChecker
+	interactor Interactor
 
+	ctx    context.Context
 	bc     bf.ByteCode
 	input  string
 	result func(Verdict)
 
 	steps  int
 	memory int
+	time   time.Duration
 }
 
 // Problem is a collection of metadata about a problem. It should be constructed directly.
 type Problem struct {
 	InputGenerator
 	OutputChecker
+	Interactor Interactor // Optional, makes the problem interactive.
 
 	Instructions int // Maximum number of active instructions.
 	Steps        int // Maximum number of steps of execution.
 	Memory       int // Maximum number of allocated bytes during the execution.
+
+	Time time.Duration // Maximum wall-clock time of a single test. Zero or negative disables the limit.
+
+	Dialect bf.Dialect // Dialect submissions are executed with.
+	Scoring Scoring    // Scoring scheme of test groups.
+
+	Comparison Comparison // How output is compared with a reference answer. See [Problem.CheckOutput].
+
+	Validator InputValidator // Optional, every generated test must pass it before judging starts.
 }
 
 // CalculateScore is a helper function to calculate score of a given verdict set.
 // Returned value is NaN for zero length v and in range [0, 1] in all other cases.
 //
 // Test group is only counted if all tests in a group pass.
+// It is a shorthand for [Scoring.Score] of a zero value [Scoring].
 func CalculateScore(v [][]Verdict) float64 {
-	var total, good int
-
-outer:
-	for _, group := range v {
-		total += len(group)
-
-		for _, test := range group {
-			if test.Status != StatusAccept {
-				continue outer
-			}
-		}
-
-		good += len(group)
-	}
-
-	return float64(good) / float64(total)
+	return Scoring{}.Score(v).Fraction()
 }
 
 // Judge judges a problem against a solution and returns a verdict.
@@ -94,6 +98,77 @@ outer:
 //   - input generation failed
 //   - on any other judge failure (should be unreachable, but who knows)
 func (j Judge) Judge(p Problem, submition string) [][]Verdict {
+	return j.JudgeContext(context.Background(), p, submition)
+}
+
+// JudgeContext is like [Judge.Judge], but stops judging once ctx is done.
+// Tests that were cancelled or never started are reported as [StatusJudgeFailed].
+func (j Judge) JudgeContext(ctx context.Context, p Problem, submition string) [][]Verdict {
+	return j.judge(ctx, p, submition, false)
+}
+
+// JudgeEarlyExit is like [Judge.JudgeContext], but once any test of a group earns no credit,
+// remaining tests of that group are cancelled and reported as [StatusSkipped].
+// Problems with [ScoringSum] scoring are always judged completely.
+//
+// Score calculated by [Scoring.Score] is the same as for [Judge.JudgeContext].
+func (j Judge) JudgeEarlyExit(ctx context.Context, p Problem, submition string) [][]Verdict {
+	return j.judge(ctx, p, submition, true)
+}
+
+func (j Judge) judge(ctx context.Context, p Problem, submition string, groupEarlyExit bool) [][]Verdict {
+	p, bc, tests, failed := prepare(p, submition)
+	if failed != nil {
+		return [][]Verdict{{*failed}}
+	}
+
+	res := make([][]Verdict, 0, len(tests))
+	for _, t := range tests {
+		res = append(res, make([]Verdict, len(t)))
+	}
+
+	for r := range j.stream(ctx, p, bc, tests, groupEarlyExit) {
+		res[r.Group][r.Test] = r.Verdict
+	}
+
+	return res
+}
+
+// TestResult is a verdict of a single test. See [Judge.Stream].
+type TestResult struct {
+	Group int // Group index of the test.
+	Test  int // Test index inside the group.
+
+	Verdict Verdict
+}
+
+// Stream is like [Judge.JudgeContext], but yields verdicts in order of completion instead of waiting for all tests.
+//
+// If groupEarlyExit is set, the first test of a group that earned no credit cancels remaining tests of that group,
+// they are reported as [StatusSkipped]. See [Judge.JudgeEarlyExit].
+//
+// Breaking out of the loop cancels all remaining tests.
+//
+// If judging can not be started, a single verdict for test 0 of group 0 is yielded, see [Judge.Judge].
+func (j Judge) Stream(ctx context.Context, p Problem, submition string, groupEarlyExit bool) iter.Seq[TestResult] {
+	return func(yield func(TestResult) bool) {
+		p, bc, tests, failed := prepare(p, submition)
+		if failed != nil {
+			yield(TestResult{Verdict: *failed})
+			return
+		}
+
+		for r := range j.stream(ctx, p, bc, tests, groupEarlyExit) {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+// prepare sets default limits of p, compiles submition and generates tests.
+// If any of that fails, verdict of the whole submition is returned.
+func prepare(p Problem, submition string) (Problem, bf.ByteCode, [][]string, *Verdict) {
 	if p.Memory <= 0 {
 		p.Memory = math.MaxInt
 	}
@@ -101,62 +176,112 @@ func (j Judge) Judge(p Problem, submition string) [][]Verdict {
 		p.Steps = math.MaxInt
 	}
 
-	bc, err := bf.Compile(submition, p.Instructions)
+	bc, err := bf.CompileDialect(submition, p.Instructions, p.Dialect)
 	if err != nil {
-		switch err.(bf.CompilationError).Kind {
+		var cerr bf.CompilationError
+		if !errors.As(err, &cerr) {
+			return p, bc, nil, &Verdict{
+				Status:  StatusJudgeFailed,
+				Comment: err.Error(),
+			}
+		}
+		switch cerr.Kind {
 		case bf.CompilationInstructionLimit:
-			return [][]Verdict{{{
+			return p, bc, nil, &Verdict{
 				Status:  StatusSourceSizeLimit,
 				Comment: "program exceeds maximum instruction count",
-			}}}
+			}
 		case bf.CompilationUnmatchedParen:
-			return [][]Verdict{{{
+			return p, bc, nil, &Verdict{
 				Status:  StatusCompilationFailed,
 				Comment: err.Error(),
-			}}}
+			}
 		default:
-			return [][]Verdict{{{
+			return p, bc, nil, &Verdict{
 				Status:  StatusJudgeFailed,
 				Comment: err.Error(),
-			}}}
+			}
 		}
 	}
 
 	tests, err := p.GenerateInput()
 	if err != nil {
-		return [][]Verdict{{{
+		return p, bc, nil, &Verdict{
 			Status:  StatusCheckerFailed,
 			Comment: err.Error(),
-		}}}
+		}
 	}
 
-	res := make([][]Verdict, 0, len(tests))
-
-	wg := new(sync.WaitGroup)
-	for _, t := range tests {
-		wg.Add(len(t))
-		res = append(res, make([]Verdict, len(t)))
+	if p.Validator != nil {
+		if v := validateTests(p.Validator, tests); v != nil {
+			return p, bc, nil, v
+		}
 	}
 
-	for groupI, group := range tests {
-		for testI, inp := range group {
-			j.jobs <- job{
-				OutputChecker: p.OutputChecker,
-				bc:            bc,
-				input:         inp,
-				result: func(v Verdict) {
-					res[groupI][testI] = v
-					wg.Done()
-				},
-				steps:  p.Steps,
-				memory: p.Memory,
-			}
+	return p, bc, tests, nil
+}
+
+// stream dispatches all tests to workers and yields their verdicts as they arrive.
+func (j Judge) stream(ctx context.Context, p Problem, bc bf.ByteCode, tests [][]string, groupEarlyExit bool) iter.Seq[TestResult] {
+	// with sum scoring every test counts
+	groupEarlyExit = groupEarlyExit && p.Scoring.Mode == ScoringMin
+
+	return func(yield func(TestResult) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		dispatched := make(chan struct{})
+		// dispatcher must not outlive the call, otherwise it may send jobs to a closed judge
+		defer func() {
+			cancel()
+			<-dispatched
+		}()
+
+		total := 0
+		groupCtx := make([]context.Context, len(tests))
+		groupCancel := make([]context.CancelCauseFunc, len(tests))
+		for i, t := range tests {
+			total += len(t)
+			groupCtx[i], groupCancel[i] = context.WithCancelCause(ctx)
 		}
-	}
 
-	wg.Wait()
+		// buffered, so workers never block even if the consumer stopped
+		results := make(chan TestResult, total)
 
-	return res
+		go func() {
+			defer close(dispatched)
+			for groupI, group := range tests {
+				gctx := groupCtx[groupI]
+				for testI, inp := range group {
+					select {
+					case j.jobs <- job{
+						OutputChecker: p, // honors p.Comparison
+						interactor:    p.Interactor,
+						ctx:           gctx,
+						bc:            bc,
+						input:         inp,
+						result: func(v Verdict) {
+							results <- TestResult{groupI, testI, v}
+						},
+						steps:  p.Steps,
+						memory: p.Memory,
+						time:   p.Time,
+					}:
+					case <-gctx.Done():
+						results <- TestResult{groupI, testI, cancelledVerdict(gctx)}
+					}
+				}
+			}
+		}()
+
+		for range total {
+			r := <-results
+			if groupEarlyExit && r.Verdict.Credit() == 0 {
+				groupCancel[r.Group](errSkipped)
+			}
+			if !yield(r) {
+				return
+			}
+		}
+	}
 }
 
 func worker(ch <-chan job) {
@@ -165,16 +290,130 @@ func worker(ch <-chan job) {
 	}
 }
 
+// errSkipped is a cancellation cause of a group, that already has a failed test.
+var errSkipped = errors.New("another test of the group failed")
+
+func cancelledVerdict(ctx context.Context) Verdict {
+	if errors.Is(context.Cause(ctx), errSkipped) {
+		return Verdict{
+			Status:  StatusSkipped,
+			Comment: "skipped: " + errSkipped.Error(),
+		}
+	}
+	return Verdict{
+		Status:  StatusJudgeFailed,
+		Comment: "judging cancelled: " + context.Cause(ctx).Error(),
+	}
+}
+
 func judgeTest(j job) Verdict {
-	out := new(bytes.Buffer)
+	if j.ctx.Err() != nil {
+		return cancelledVerdict(j.ctx)
+	}
+
+	timeLimit := j.time
+	if j.interactor != nil && timeLimit <= 0 {
+		// submission and interactor can wait for each other forever
+		timeLimit = DefaultTimeLimit
+	}
+
+	ctx := j.ctx
+	if timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeLimit)
+		defer cancel()
+	}
+
+	if j.interactor != nil {
+		return judgeInteractive(ctx, j)
+	}
+
+	out := new(limitedBuffer)
 	s := bf.NewState(j.bc, strings.NewReader(j.input), out, j.steps, j.memory)
 
-	if err := s.Run(); err != nil {
+	start := time.Now()
+	err := s.RunContext(ctx)
+	elapsed := time.Since(start)
+
+	var v Verdict
+	if err != nil {
+		if j.ctx.Err() != nil {
+			return cancelledVerdict(j.ctx)
+		}
+		v = runtimeErrorVerdict(j.bc, &s, err)
+	} else {
+		v = j.CheckOutput(j.input, out.String())
+	}
+
+	return withUsage(v, j, &s, out.Len(), elapsed)
+}
+
+// withUsage records resource usage of a finished submission in v.
+func withUsage(v Verdict, j job, s *bf.State, output int, elapsed time.Duration) Verdict {
+	v.Steps = j.steps - s.RemainingSteps()
+	v.Memory = s.UsedMemory()
+	v.Output = output
+	v.Time = elapsed
+	return v
+}
+
+// runtimeErrorVerdict classifies an error returned by [bf.State.RunContext].
+func runtimeErrorVerdict(bc bf.ByteCode, s *bf.State, err error) Verdict {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Verdict{
+			Status:  StatusTimeLimit,
+			Comment: "time limit exceeded",
+		}
+	case errors.Is(err, bf.RuntimeErrorStepLimit):
+		return Verdict{
+			Status:  StatusTimeLimit,
+			Comment: runtimeErrorComment(bc, s, "step limit exceeded"),
+		}
+	case errors.Is(err, bf.RuntimeErrorMemoryLimit):
+		return Verdict{
+			Status:  StatusMemoryLimit,
+			Comment: runtimeErrorComment(bc, s, "memory limit exceeded"),
+		}
+	case errors.Is(err, io.EOF):
+		return Verdict{
+			Status:  StatusInputExhausted,
+			Comment: runtimeErrorComment(bc, s, "read past the end of input"),
+		}
+	case errors.Is(err, errOutputLimit):
+		return Verdict{
+			Status:  StatusOutputLimit,
+			Comment: runtimeErrorComment(bc, s, err.Error()),
+		}
+	default:
 		return Verdict{
 			Status:  StatusRuntimeError,
-			Comment: err.Error(),
+			Comment: runtimeErrorComment(bc, s, err.Error()),
 		}
 	}
+}
+
+// runtimeErrorComment returns msg with location of the offending instruction, if it is known.
+func runtimeErrorComment(bc bf.ByteCode, s *bf.State, msg string) string {
+	if pos, ok := bc.Position(s.ErrorInstruction()); ok {
+		return fmt.Sprintf("%s at %v", msg, pos)
+	}
+	return msg
+}
+
+// MaxOutput is a maximum number of bytes a submission can output on a single test.
+const MaxOutput = 16 << 20
+
+var errOutputLimit = fmt.Errorf("output limit of %d bytes exceeded", MaxOutput)
 
-	return j.CheckOutput(j.input, out.String())
+// limitedBuffer is a [bytes.Buffer] that fails writes past [MaxOutput] bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) WriteByte(c byte) error {
+	if b.Len() >= MaxOutput {
+		return errOutputLimit
+	}
+	return b.Buffer.WriteByte(c)
 }
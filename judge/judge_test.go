@@ -1,7 +1,10 @@
 package judge_test
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TrueHopolok/braincode-/judge"
 )
@@ -78,6 +81,86 @@ func TestLength(t *testing.T) {
 	runTest(t, checker, good, bad)
 }
 
+func TestTimeLimit(t *testing.T) {
+	J := judge.NewJudge(2)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a", "b"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", ""}, judge.Pair{"b", ""}),
+		Steps:          1e15,
+		Memory:         100,
+		Time:           50 * time.Millisecond,
+	}
+
+	got := J.Judge(p, `+[[-]+]`)
+	for _, v := range got[0] {
+		if v.Status != judge.StatusTimeLimit {
+			t.Errorf("got %v want %v", v, judge.StatusTimeLimit)
+		}
+	}
+}
+
+func TestRuntimeErrorLocation(t *testing.T) {
+	J := judge.NewJudge(1)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", "a"}),
+		Steps:          100,
+		Memory:         100,
+	}
+
+	got := J.Judge(p, ",.\n <")
+	if v := got[0][0]; v.Status != judge.StatusRuntimeError || !strings.HasSuffix(v.Comment, "at line 2, column 2") {
+		t.Errorf("got %v want runtime error at line 2, column 2", v)
+	}
+}
+
+func TestProfileSlowest(t *testing.T) {
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"\x01", "\x05"}, {"\x02"}}),
+		OutputChecker:  judge.NewListSolutionSlice(),
+		Steps:          1000,
+		Memory:         100,
+	}
+
+	prof, err := judge.ProfileSlowest(context.Background(), p, ",[>+<-]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prof.Group != 0 || prof.Test != 1 {
+		t.Errorf("profiled test %d.%d want 0.1", prof.Group, prof.Test)
+	}
+	if len(prof.Loops) != 1 || prof.Loops[0].Iterations != 5 {
+		t.Errorf("loops %+v want a single loop with 5 iterations", prof.Loops)
+	}
+}
+
+func TestJudgeCancelled(t *testing.T) {
+	J := judge.NewJudge(1)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a", "b", "c"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", ""}, judge.Pair{"b", ""}, judge.Pair{"c", ""}),
+		Steps:          1e15,
+		Memory:         100,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	got := J.JudgeContext(ctx, p, `+[[-]+]`)
+	for _, v := range got[0] {
+		if v.Status != judge.StatusJudgeFailed {
+			t.Errorf("got %v want %v", v, judge.StatusJudgeFailed)
+		}
+	}
+}
+
 func runTest(t *testing.T, checker, goodTest, badTest string) {
 	t.Helper()
 
@@ -116,3 +199,137 @@ func runTest(t *testing.T, checker, goodTest, badTest string) {
 		t.Errorf("got %q (score %v > 0)", got, s)
 	}
 }
+
+func TestLimitStatuses(t *testing.T) {
+	J := judge.NewJudge(2)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", "a"}),
+		Steps:          100,
+		Memory:         10,
+	}
+
+	tests := []struct {
+		submition string
+		status    judge.Status
+	}{
+		{",.", judge.StatusAccept},
+		{">>>>>>>>>>>>", judge.StatusMemoryLimit},
+		{"+[]", judge.StatusTimeLimit},
+		{",.,", judge.StatusInputExhausted},
+		{"<", judge.StatusRuntimeError},
+	}
+
+	for _, tt := range tests {
+		v := J.Judge(p, tt.submition)[0][0]
+		if v.Status != tt.status {
+			t.Errorf("%q: got %v want %v", tt.submition, v, tt.status)
+		}
+		if v.Steps <= 0 || v.Memory <= 0 || v.Time <= 0 {
+			t.Errorf("%q: resource usage is not reported: %+v", tt.submition, v)
+		}
+		if tt.status == judge.StatusAccept && v.Output != 1 {
+			t.Errorf("%q: output length %d want 1", tt.submition, v.Output)
+		}
+	}
+}
+
+func TestOutputLimit(t *testing.T) {
+	J := judge.NewJudge(1)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{""}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"", ""}),
+		Steps:          1e9,
+		Memory:         10,
+	}
+
+	v := J.Judge(p, "+[.]")[0][0]
+	if v.Status != judge.StatusOutputLimit {
+		t.Errorf("got %v want %v", v, judge.StatusOutputLimit)
+	}
+}
+
+func TestStream(t *testing.T) {
+	J := judge.NewJudge(2)
+	defer J.Close()
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a", "b", "c"}, {"x", "y"}}),
+		OutputChecker: judge.NewListSolutionSlice(
+			judge.Pair{"a", "?"}, judge.Pair{"b", "?"}, judge.Pair{"c", "?"},
+			judge.Pair{"x", "x"}, judge.Pair{"y", "y"},
+		),
+		Steps:  100,
+		Memory: 100,
+	}
+
+	counts := make([]int, 2)
+	for r := range J.Stream(context.Background(), p, ",.", true) {
+		counts[r.Group]++
+		if r.Group == 1 && r.Verdict.Status != judge.StatusAccept {
+			t.Errorf("test %d.%d: got %v want %v", r.Group, r.Test, r.Verdict, judge.StatusAccept)
+		}
+	}
+	if counts[0] != 3 || counts[1] != 2 {
+		t.Errorf("got %v results per group want [3 2]", counts)
+	}
+
+	n := 0
+	for range J.Stream(context.Background(), p, ",.", false) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d results after break want 1", n)
+	}
+
+	for r := range J.Stream(context.Background(), p, "[", false) {
+		if r.Verdict.Status != judge.StatusCompilationFailed {
+			t.Errorf("got %v want %v", r.Verdict, judge.StatusCompilationFailed)
+		}
+	}
+}
+
+func TestJudgeEarlyExit(t *testing.T) {
+	J := judge.NewJudge(1)
+	defer J.Close()
+
+	group := make([]string, 200)
+	for i := range group {
+		group[i] = "a"
+	}
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{group, {"b"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", "?"}, judge.Pair{"b", "b"}),
+		Steps:          100,
+		Memory:         100,
+	}
+
+	got := J.JudgeEarlyExit(context.Background(), p, ",.")
+
+	var wrong, skipped int
+	for _, v := range got[0] {
+		switch v.Status {
+		case judge.StatusWrongAnswer:
+			wrong++
+		case judge.StatusSkipped:
+			skipped++
+		default:
+			t.Errorf("got %v want %v or %v", v, judge.StatusWrongAnswer, judge.StatusSkipped)
+		}
+	}
+	if wrong == 0 || skipped == 0 {
+		t.Errorf("got %d wrong answers and %d skipped tests, want both to be positive", wrong, skipped)
+	}
+	if v := got[1][0]; v.Status != judge.StatusAccept {
+		t.Errorf("got %v want %v", v, judge.StatusAccept)
+	}
+	if s := judge.CalculateScore(got); s != 1.0/201 {
+		t.Errorf("score %v want %v", s, 1.0/201)
+	}
+}
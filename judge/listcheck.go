@@ -1,6 +1,7 @@
 package judge
 
 import (
+	"errors"
 	"iter"
 	"maps"
 )
@@ -8,7 +9,7 @@ import (
 type listSolution map[string]string
 
 // NewListSolution creates a new table driven test checker. Input must map input to output.
-// Output must match exactly.
+// Output must match exactly, unless [Problem.Comparison] is set.
 // Checker will fail for any input not present in answers.
 func NewListSolution(answers iter.Seq2[string, string]) OutputChecker {
 	return listSolution(maps.Collect(answers))
@@ -25,21 +26,15 @@ func NewListSolutionSlice(answers ...Pair) OutputChecker {
 }
 
 func (l listSolution) CheckOutput(input string, output string) Verdict {
+	return checkAnswer(l, Comparison{}, input, output)
+}
+
+func (l listSolution) answer(input string) (string, string, error) {
 	v, found := l[input]
 	if !found {
-		return Verdict{
-			Status:  StatusCheckerFailed,
-			Comment: "checker has no answer",
-		}
+		return "", "", errors.New("checker has no answer")
 	}
-
-	if v != output {
-		return Verdict{
-			Status: StatusWrongAnswer,
-		}
-	}
-
-	return Verdict{}
+	return v, "", nil
 }
 
 type pairs []Pair
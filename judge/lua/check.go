@@ -36,6 +36,26 @@ import (
 //   - true value is considered a failure without comment.
 //   - any other value is considered a comment, will be stringified using tostring() and passes to the judge.
 //
+// # Token streams
+//
+// Checker mode provides testlib-like streams inp and out over test input and submition output.
+// Streams have following methods:
+//   - s:readToken() reads a whitespace separated token.
+//   - s:readInt([min, [max]]) reads an integer, optionally checking that it is in range [min, max].
+//   - s:readNumber([min, [max]]) reads a decimal number, optionally checking that it is in range [min, max].
+//   - s:readLine() reads the rest of the current line, without line terminator.
+//   - s:eof() reports whether the stream is exhausted.
+//   - s:seekEOF() skips whitespace and reports whether the stream is exhausted.
+//   - s:expectEOF() skips whitespace and fails if the stream is not exhausted.
+//
+// Malformed data in out fails the test with a "wrong output format" comment,
+// while malformed data in inp is a checker failure. stream(s, [name]) creates a new stream over a string.
+//
+// Following functions stop the checker immediately, overriding its return value:
+//   - ok() passes the test.
+//   - fail([format, ...]) fails the test with a comment formatted by string.format.
+//   - partial(score, [format, ...]) gives a partial credit with a comment formatted by string.format.
+//
 // Checker mode is always preferred over solution mode. At least one of 2 functions must be defined.
 //
 // Zero value checker is invalid, use [NewChecker] to construct one. It is safe to copy and use concurrently, because it is immutable.
@@ -46,7 +66,10 @@ type Checker struct {
 	useSolution bool
 }
 
-var ErrNotAChecker = errors.New("not a checker")
+var (
+	ErrNotAChecker = errors.New("not a checker")
+	ErrNoSolution  = errors.New("checker does not define a solution")
+)
 
 // NewChecker parses source and creates a new Checker.
 func NewChecker(source string) (Checker, error) {
@@ -97,6 +120,17 @@ func newChecker(source string, chunks []ast.Stmt) (Checker, error) {
 // If string is empty, test passes.
 // Otherwise, string will contain a possibly multiline checker comment.
 func (c Checker) CheckOutput(input, output string) (string, error) {
+	comment, score, err := c.CheckScore(input, output)
+	if err != nil || score >= 1 {
+		return "", err
+	}
+	return cmp.Or(comment, fmt.Sprintf("partial credit %g", score)), nil
+}
+
+// CheckScore is like [Checker.CheckOutput], but also returns credit of the test in range [0, 1].
+// Credit is either 0 or 1, unless a checker called partial.
+// Comment is empty for tests with full credit.
+func (c Checker) CheckScore(input, output string) (comment string, score float64, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
@@ -107,7 +141,7 @@ func (c Checker) CheckOutput(input, output string) (string, error) {
 	lfunc := l.NewFunctionFromProto(c.compiled)
 	l.Push(lfunc)
 	if err := l.PCall(0, lua.MultRet, nil); err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	if c.useSolution {
@@ -117,46 +151,106 @@ func (c Checker) CheckOutput(input, output string) (string, error) {
 	}
 }
 
-func (c Checker) runSolution(l luaState, input, output string) (string, error) {
+// Solution returns the answer of a checker in solution mode and standard output of the script, that is used as a comment.
+// Numbers returned by the solution function are converted to strings.
+// Returns [ErrNoSolution] if checker is in checker mode.
+func (c Checker) Solution(input string) (answer, comment string, err error) {
+	if !c.useSolution {
+		return "", "", ErrNoSolution
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	l := newLuaState()
+	defer l.Close()
+	l.SetContext(ctx)
+
+	l.Push(l.NewFunctionFromProto(c.compiled))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return "", "", err
+	}
+
 	if err := l.CallByParam(lua.P{
 		Fn:      l.GetGlobal("solution"),
 		NRet:    1,
 		Protect: true,
 	}, lua.LString(input)); err != nil {
-		return "", err
+		return "", "", err
+	}
+
+	ret := l.Get(-1)
+	if !lua.LVCanConvToString(ret) {
+		return "", "", fmt.Errorf("solution returned %s, expected a string", ret.Type())
+	}
+	return lua.LVAsString(ret), l.Buffer.String(), nil
+}
+
+func (c Checker) runSolution(l luaState, input, output string) (string, float64, error) {
+	if err := l.CallByParam(lua.P{
+		Fn:      l.GetGlobal("solution"),
+		NRet:    1,
+		Protect: true,
+	}, lua.LString(input)); err != nil {
+		return "", 0, err
 	}
 
 	ret := l.CheckString(-1)
 
 	if ret != output {
-		return l.Buffer.String() + "result do not match", nil
+		return l.Buffer.String() + "result do not match", 0, nil
 	}
 
-	return "", nil
+	return "", 1, nil
 }
 
-func (c Checker) runChecker(l luaState, input, output string) (string, error) {
-	if err := l.CallByParam(lua.P{
+func (c Checker) runChecker(l luaState, input, output string) (string, float64, error) {
+	setStreams(l.LState, input, output)
+
+	err := l.CallByParam(lua.P{
 		Fn:      l.GetGlobal("checker"),
 		NRet:    1,
 		Protect: true,
-	}, lua.LString(input), lua.LString(output)); err != nil {
-		return "", err
+	}, lua.LString(input), lua.LString(output))
+	if comment, score, ok := quitted(l); ok {
+		return comment, score, nil
+	}
+	if err != nil {
+		return "", 0, err
 	}
 
-	ret := l.Get(-1)
+	comment, score := checkerResult(l, l.Get(-1))
+	return comment, score, nil
+}
 
+// checkerResult interprets a value returned by a checker function.
+// See [Checker] for accepted values.
+func checkerResult(l luaState, ret lua.LValue) (comment string, score float64) {
 	if lua.LVAsBool(ret) && ret != lua.LString("") {
 		if ret == lua.LTrue {
-			return cmp.Or(l.Buffer.String(), "test failed"), nil
+			return cmp.Or(l.Buffer.String(), "test failed"), 0
 		}
 
-		return l.Buffer.String() + ret.String(), nil
+		return l.Buffer.String() + ret.String(), 0
 	}
 
-	return "", nil
+	return "", 1
 }
 
+// quitted returns a result reported by ok, fail or partial functions, if any.
+func quitted(l luaState) (comment string, score float64, ok bool) {
+	if !l.quit.done {
+		return "", 0, false
+	}
+	if l.quit.score >= 1 {
+		return "", 1, true
+	}
+	return l.Buffer.String() + l.quit.comment, l.quit.score, true
+}
+
+// Source returns lua source code the checker was created from.
+func (c Checker) Source() string { return c.source }
+
 type checkerSource string
 
 func (c Checker) AppendBinary(b []byte) ([]byte, error) {
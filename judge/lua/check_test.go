@@ -30,3 +30,134 @@ func TestPrefersCheckerOverSolution(t *testing.T) {
 		t.Error("c.useSolution is true, want false")
 	}
 }
+
+func TestCheckScore(t *testing.T) {
+	const source = `
+		function checker(input, output)
+			print("close")
+			partial(0.25)
+		end
+	`
+
+	c, err := NewChecker(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	comment, score, err := c.CheckScore("", "")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if score != 0.25 || comment != "close\n" {
+		t.Errorf("got %q with score %v want %q with score 0.25", comment, score, "close\n")
+	}
+
+	if res, _ := c.CheckOutput("", ""); res != "close\n" {
+		t.Errorf("CheckOutput = %q want %q", res, "close\n")
+	}
+
+	c, err = NewChecker(`function checker(input, output) return 1 end`)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if comment, score, _ := c.CheckScore("", ""); score != 0 || comment != "1" {
+		t.Errorf("number: got %q with score %v want %q with score 0", comment, score, "1")
+	}
+}
+
+func TestTokenStreams(t *testing.T) {
+	const source = `
+		function checker(input, output)
+			local n = inp:readInt(1, 100)
+			local sum = 0
+			for i = 1, n do
+				sum = sum + inp:readInt()
+			end
+			local got = out:readInt()
+			out:expectEOF()
+			if got ~= sum then
+				fail("expected %d, found %d", sum, got)
+			end
+			print("unreachable")
+			ok()
+		end
+	`
+
+	c, err := NewChecker(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		input, output string
+		comment       string
+		wantErr       bool
+	}{
+		{"3\n1 2 3\n", "6\n", "", false},
+		{"3\n1 2 3\n", "  6  ", "", false},
+		{"3\n1 2 3\n", "7\n", "expected 6, found 7", false},
+		{"3\n1 2 3\n", "six\n", `wrong output format: expected integer, found "six"`, false},
+		{"3\n1 2 3\n", "", "wrong output format: expected integer, found end of file", false},
+		{"3\n1 2 3\n", "6 6\n", `wrong output format: expected end of file, found "6"`, false},
+		{"3\n1 2 3\n", "+6\n", `wrong output format: expected integer, found "+6"`, false},
+		{"300\n", "0\n", "", true},
+		{"2\n1\n", "1\n", "", true},
+	}
+
+	for _, tt := range tests {
+		comment, err := c.CheckOutput(tt.input, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckOutput(%q, %q) err = %v, want error %v", tt.input, tt.output, err, tt.wantErr)
+			continue
+		}
+		if comment != tt.comment {
+			t.Errorf("CheckOutput(%q, %q) = %q want %q", tt.input, tt.output, comment, tt.comment)
+		}
+	}
+}
+
+func TestStreamReaders(t *testing.T) {
+	const source = `
+		function checker(input, output)
+			local s = stream("first line\r\n 2.5e1  word\n")
+			if s:readLine() ~= "first line" then return "readLine" end
+			if s:readNumber(0, 30) ~= 25 then return "readNumber" end
+			if s:eof() then return "eof" end
+			if s:readToken() ~= "word" then return "readToken" end
+			if not s:seekEOF() then return "seekEOF" end
+			if pcall(s.readLine, s) then return "readLine at end of file" end
+		end
+	`
+
+	c, err := NewChecker(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if comment, err := c.CheckOutput("", ""); err != nil || comment != "" {
+		t.Errorf("CheckOutput = %q, %v want empty comment", comment, err)
+	}
+}
+
+func TestQuitOverridesPcall(t *testing.T) {
+	const source = `
+		function checker(input, output)
+			pcall(partial, 0.5, "half")
+			pcall(ok)
+			return false
+		end
+	`
+
+	c, err := NewChecker(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	comment, score, err := c.CheckScore("", "")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if score != 0.5 || comment != "half" {
+		t.Errorf("got %q with score %v want %q with score 0.5", comment, score, "half")
+	}
+}
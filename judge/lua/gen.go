@@ -10,6 +10,9 @@ import (
 
 const genTimeout = 2 * time.Second
 
+// maxTests is the maximum number of tests or groups a generator may produce.
+const maxTests = 100_000
+
 // GetTests extracts tests from lua source.
 //
 // Tests can be provided in of 2 ways.
@@ -34,6 +37,10 @@ const genTimeout = 2 * time.Second
 //
 // A function called test_data may be provided. It will be called with zero arguments and must return
 // a string | (nil | string | (nil | string)[])[]
+//
+// # Seeded tests
+//
+// See [GetSeededTests] for tests reproducible from a seed with declared groups and parameters.
 func GetTests(source string) ([][]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), genTimeout)
 	defer cancel()
@@ -73,7 +80,7 @@ func parseTests(v lua.LValue) ([][]string, error) {
 		return [][]string{{string(v)}}, nil
 	case *lua.LTable:
 		var res [][]string
-		for i := 1; i <= min(v.MaxN(), 100_000); i++ {
+		for i := 1; i <= min(v.MaxN(), maxTests); i++ {
 			vv := v.RawGetInt(i)
 			switch vv := vv.(type) {
 			case *lua.LNilType:
@@ -84,7 +91,7 @@ func parseTests(v lua.LValue) ([][]string, error) {
 
 			case *lua.LTable:
 				var group []string
-				for j := 1; j <= min(v.MaxN(), 100_000); j++ {
+				for j := 1; j <= min(v.MaxN(), maxTests); j++ {
 					vvv := vv.RawGetInt(j)
 					switch vvv := vvv.(type) {
 					case *lua.LNilType:
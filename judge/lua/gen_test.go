@@ -1,6 +1,7 @@
 package lua_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -95,3 +96,93 @@ func TestGetTests(t *testing.T) {
 		})
 	}
 }
+
+func TestSeedGenerator(t *testing.T) {
+	g, err := lua.NewSeedGenerator(`
+		function test_data(seed, params)
+			return seed .. ":" .. random(params.max)
+		end
+	`, map[string]string{"max": "1000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := g.Generate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := g.Generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := g.Generate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != again {
+		t.Errorf("same seed generated %q and %q", a, again)
+	}
+	if a == b {
+		t.Errorf("different seeds generated the same input %q", a)
+	}
+
+	if _, err := lua.NewSeedGenerator(`test_data = "x"`, nil); !errors.Is(err, lua.ErrNotASeedGenerator) {
+		t.Errorf("err = %v want %v", err, lua.ErrNotASeedGenerator)
+	}
+}
+
+func TestGetSeededTests(t *testing.T) {
+	const source = `
+		function test_data(seed, params)
+			local res = params.size .. ":"
+			for i = 1, params.n do
+				res = res .. random(9)
+			end
+			return res
+		end
+	`
+
+	groups, err := lua.ParseGroupSpecs("tests=2 seed=10 n=3 size=small\n\n  seed=100 n=20 size=large  \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lua.GroupSpec{
+		{Tests: 2, Seed: 10, Params: map[string]string{"n": "3", "size": "small"}},
+		{Tests: 1, Seed: 100, Params: map[string]string{"n": "20", "size": "large"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("ParseGroupSpecs = %v want %v", groups, want)
+	}
+
+	tests, err := lua.GetSeededTests(source, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 || len(tests[0]) != 2 || len(tests[1]) != 1 {
+		t.Fatalf("got %q", tests)
+	}
+	if len(tests[0][0]) != len("small:")+3 || len(tests[1][0]) != len("large:")+20 {
+		t.Errorf("got %q", tests)
+	}
+	if tests[0][0] == tests[0][1] {
+		t.Errorf("tests with different seeds are equal: %q", tests[0])
+	}
+
+	// a single test is reproducible from its seed
+	again, err := lua.GetSeededTests(source, []lua.GroupSpec{{Tests: 1, Seed: groups[0].TestSeed(1), Params: groups[0].Params}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0][0] != tests[0][1] {
+		t.Errorf("reproduced %q want %q", again[0][0], tests[0][1])
+	}
+}
+
+func TestParseGroupSpecsErrors(t *testing.T) {
+	for _, s := range []string{"tests=0", "tests=x", "seed=1.5", "n", "n=", "=1", "n=1 n=2"} {
+		if _, err := lua.ParseGroupSpecs(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
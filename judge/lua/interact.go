@@ -0,0 +1,146 @@
+package lua
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// Interactor contains a parsed lua script to be used for judging interactive problems.
+//
+// A function with signature interactor(input) must be defined. It will be called with test input
+// and talks to the submission using following functions:
+//   - read(n) reads up to n bytes (1 if omitted) of submission output. Returns nil if submission terminated.
+//   - readline() reads submission output up to a newline, which is not included. Returns nil if submission terminated.
+//   - write(...) writes all string arguments to submission input. Returns false if submission terminated.
+//
+// Return value is interpreted in the same way as in the checker mode of [Checker].
+// Functions ok, fail, partial and stream are available too.
+// Submission input is closed once the function returns.
+//
+// Script is sandboxed and global state is wiped between tests.
+//
+// Zero value interactor is invalid, use [NewInteractor] to construct one. It is safe to copy and use concurrently, because it is immutable.
+type Interactor struct {
+	source   string // used for serialization only
+	compiled *lua.FunctionProto
+}
+
+var ErrNotAnInteractor = errors.New("not an interactor")
+
+// NewInteractor parses source and creates a new Interactor.
+func NewInteractor(source string) (Interactor, error) {
+	chunks, err := parse.Parse(strings.NewReader(source), "interactor.lua")
+	if err != nil {
+		return Interactor{}, fmt.Errorf("parse failed: %w", err)
+	}
+
+	f, err := lua.Compile(chunks, "interactor.lua")
+	if err != nil {
+		return Interactor{}, fmt.Errorf("compilation failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	s := newLuaState()
+	defer s.Close()
+	s.SetContext(ctx)
+
+	s.Push(s.NewFunctionFromProto(f))
+	if err := s.PCall(0, lua.MultRet, nil); err != nil {
+		return Interactor{}, fmt.Errorf("initial execution failed: %w", err)
+	}
+
+	if s.GetGlobal("interactor").Type() != lua.LTFunction {
+		return Interactor{}, fmt.Errorf("%w: interactor function must be defined", ErrNotAnInteractor)
+	}
+
+	return Interactor{
+		source:   source,
+		compiled: f,
+	}, nil
+}
+
+// Interact runs the interactor until it returns or ctx is done.
+// Submission output is read from r and its input is written to w.
+//
+// Non nil error means that interactor has failed.
+// Otherwise comment and score have the same meaning as in [Checker.CheckScore].
+//
+// Reads and writes are not interrupted by ctx, caller must close r and w to unblock them.
+func (it Interactor) Interact(ctx context.Context, input string, r io.Reader, w io.Writer) (comment string, score float64, err error) {
+	l := newLuaState()
+	defer l.Close()
+	l.SetContext(ctx)
+
+	br := bufio.NewReader(r)
+
+	l.SetGlobal("read", l.NewFunction(func(l *lua.LState) int {
+		n := l.OptInt(1, 1)
+		if n < 0 {
+			l.ArgError(1, "must not be negative")
+		}
+
+		buf := make([]byte, n)
+		got, err := io.ReadFull(br, buf)
+		if err != nil && got == 0 && n > 0 {
+			l.Push(lua.LNil)
+			return 1
+		}
+		l.Push(lua.LString(buf[:got]))
+		return 1
+	}))
+
+	l.SetGlobal("readline", l.NewFunction(func(l *lua.LState) int {
+		line, err := br.ReadBytes('\n')
+		if err != nil && len(line) == 0 {
+			l.Push(lua.LNil)
+			return 1
+		}
+		l.Push(lua.LString(bytes.TrimSuffix(line, []byte{'\n'})))
+		return 1
+	}))
+
+	l.SetGlobal("write", l.NewFunction(func(l *lua.LState) int {
+		for i := 1; i <= l.GetTop(); i++ {
+			if _, err := io.WriteString(w, l.CheckString(i)); err != nil {
+				l.Push(lua.LFalse)
+				return 1
+			}
+		}
+		l.Push(lua.LTrue)
+		return 1
+	}))
+
+	l.Push(l.NewFunctionFromProto(it.compiled))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return "", 0, err
+	}
+
+	err = l.CallByParam(lua.P{
+		Fn:      l.GetGlobal("interactor"),
+		NRet:    1,
+		Protect: true,
+	}, lua.LString(input))
+	if comment, score, ok := quitted(l); ok {
+		return comment, score, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	comment, score = checkerResult(l, l.Get(-1))
+	return comment, score, nil
+}
+
+// Source returns lua source code the interactor was created from.
+func (it Interactor) Source() string { return it.source }
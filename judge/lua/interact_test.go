@@ -0,0 +1,50 @@
+package lua
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInteractor(t *testing.T) {
+	const source = `
+		function interactor(input)
+			write("name?\n")
+			local name = readline()
+			if name ~= input then
+				return "got " .. tostring(name)
+			end
+			if read() ~= nil then
+				return "unexpected output"
+			end
+		end
+	`
+
+	it, err := NewInteractor(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	w := new(bytes.Buffer)
+	comment, score, err := it.Interact(context.Background(), "bob", strings.NewReader("bob\n"), w)
+	if err != nil || score != 1 {
+		t.Errorf("got %q with score %v (err = %v) want a success", comment, score, err)
+	}
+	if w.String() != "name?\n" {
+		t.Errorf("written %q want %q", w, "name?\n")
+	}
+
+	comment, score, err = it.Interact(context.Background(), "bob", strings.NewReader("alice"), w)
+	if err != nil || score != 0 || comment != "got alice" {
+		t.Errorf("got %q with score %v (err = %v) want %q", comment, score, err, "got alice")
+	}
+}
+
+func TestNotAnInteractor(t *testing.T) {
+	_, err := NewInteractor(`function checker(input, output) end`)
+	if !errors.Is(err, ErrNotAnInteractor) {
+		t.Errorf("err = %v want %v", err, ErrNotAnInteractor)
+	}
+}
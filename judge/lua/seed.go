@@ -0,0 +1,229 @@
+package lua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// GroupSpec declares a group of tests generated by calling test_data(seed, params) once per test.
+// See [GetSeededTests].
+type GroupSpec struct {
+	Tests  int               // Number of tests in the group.
+	Seed   int64             // Seed of the first test, following tests use consecutive seeds.
+	Params map[string]string // Named parameters passed to test_data.
+}
+
+// TestSeed returns seed of the i-th test of the group.
+func (g GroupSpec) TestSeed(i int) int64 {
+	return g.Seed + int64(i)
+}
+
+// String formats group in a format accepted by [ParseGroupSpecs].
+func (g GroupSpec) String() string {
+	fields := []string{
+		"tests=" + strconv.Itoa(g.Tests),
+		"seed=" + strconv.FormatInt(g.Seed, 10),
+	}
+	for _, k := range slices.Sorted(maps.Keys(g.Params)) {
+		fields = append(fields, k+"="+g.Params[k])
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseGroupSpecs parses group declarations, one group per non empty line.
+// Group is a whitespace separated list of key=value pairs. Keys "tests" (1 if omitted) and "seed" (0 if omitted)
+// are reserved, all other pairs are passed to test_data as named parameters.
+func ParseGroupSpecs(s string) ([]GroupSpec, error) {
+	var res []GroupSpec
+	for i, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		g := GroupSpec{Tests: 1}
+		for _, f := range fields {
+			key, value, ok := strings.Cut(f, "=")
+			if !ok || key == "" || value == "" {
+				return nil, fmt.Errorf("line %d: %q is not a key=value pair", i+1, f)
+			}
+
+			var err error
+			switch key {
+			case "tests":
+				g.Tests, err = strconv.Atoi(value)
+				if err == nil && (g.Tests < 1 || g.Tests > maxTests) {
+					err = fmt.Errorf("must be in range [1, %d]", maxTests)
+				}
+			case "seed":
+				g.Seed, err = strconv.ParseInt(value, 10, 64)
+			default:
+				if _, dup := g.Params[key]; dup {
+					err = errors.New("duplicate parameter")
+				}
+				if g.Params == nil {
+					g.Params = make(map[string]string)
+				}
+				g.Params[key] = value
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", i+1, key, err)
+			}
+		}
+
+		res = append(res, g)
+	}
+	return res, nil
+}
+
+// GetSeededTests generates tests of every declared group.
+//
+// A function called test_data must be defined in source. It will be called once per test with the seed of the test
+// and a table of group parameters, and must return a string. Parameter values that are numbers are passed as numbers.
+// Random number generator is seeded with the seed of the test before the script is executed,
+// so every test can be reproduced from its seed alone.
+func GetSeededTests(source string, groups []GroupSpec) ([][]string, error) {
+	total := 0
+	for _, g := range groups {
+		total += g.Tests
+	}
+	if total > maxTests {
+		return nil, fmt.Errorf("too many tests: %d, at most %d are allowed", total, maxTests)
+	}
+	if total == 0 {
+		return nil, errors.New("empty test data")
+	}
+
+	f, err := compileGenerator(source)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), genTimeout)
+	defer cancel()
+
+	res := make([][]string, len(groups))
+	for i, g := range groups {
+		res[i] = make([]string, g.Tests)
+		for j := range g.Tests {
+			res[i][j], err = seededTest(ctx, f, g.TestSeed(j), g.Params)
+			if err != nil {
+				return nil, fmt.Errorf("group %d test %d (seed %d): %w", i+1, j+1, g.TestSeed(j), err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// SeedGenerator contains a parsed lua script that generates single tests from a seed,
+// calling test_data(seed, params) in the same way as [GetSeededTests] does.
+// It is used to generate arbitrary many inputs, for example to stress test a solution.
+//
+// Zero value generator is invalid, use [NewSeedGenerator] to construct one. It is safe to copy and use concurrently, because it is immutable.
+type SeedGenerator struct {
+	compiled *lua.FunctionProto
+	params   map[string]string
+}
+
+var ErrNotASeedGenerator = errors.New("not a seed generator")
+
+// NewSeedGenerator parses source and creates a new SeedGenerator, that passes params to every call of test_data.
+func NewSeedGenerator(source string, params map[string]string) (SeedGenerator, error) {
+	f, err := compileGenerator(source)
+	if err != nil {
+		return SeedGenerator{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	l := newLuaState()
+	defer l.Close()
+	l.SetContext(ctx)
+
+	l.Push(l.NewFunctionFromProto(f))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return SeedGenerator{}, fmt.Errorf("initial execution failed: %w", err)
+	}
+	if l.GetGlobal("test_data").Type() != lua.LTFunction {
+		return SeedGenerator{}, fmt.Errorf("%w: test_data function must be defined", ErrNotASeedGenerator)
+	}
+
+	return SeedGenerator{compiled: f, params: params}, nil
+}
+
+// Generate returns test input generated from seed.
+func (g SeedGenerator) Generate(seed int64) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), genTimeout)
+	defer cancel()
+
+	return seededTest(ctx, g.compiled, seed, g.params)
+}
+
+func compileGenerator(source string) (*lua.FunctionProto, error) {
+	chunks, err := parse.Parse(strings.NewReader(source), "generator.lua")
+	if err != nil {
+		return nil, fmt.Errorf("parse failed: %w", err)
+	}
+	f, err := lua.Compile(chunks, "generator.lua")
+	if err != nil {
+		return nil, fmt.Errorf("compilation failed: %w", err)
+	}
+	return f, nil
+}
+
+func seededTest(ctx context.Context, f *lua.FunctionProto, seed int64, params map[string]string) (string, error) {
+	l := newLuaState()
+	defer l.Close()
+	l.SetContext(ctx)
+
+	if err := l.CallByParam(lua.P{
+		Fn:      l.GetGlobal("randomseed"),
+		Protect: true,
+	}, lua.LNumber(seed)); err != nil {
+		return "", err
+	}
+
+	l.Push(l.NewFunctionFromProto(f))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return "", err
+	}
+
+	fn := l.GetGlobal("test_data")
+	if fn.Type() != lua.LTFunction {
+		return "", errors.New("test_data function must be defined")
+	}
+
+	t := l.NewTable()
+	for k, v := range params {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+			t.RawSetString(k, lua.LNumber(n))
+		} else {
+			t.RawSetString(k, lua.LString(v))
+		}
+	}
+
+	if err := l.CallByParam(lua.P{
+		Fn:      fn,
+		NRet:    1,
+		Protect: true,
+	}, lua.LNumber(seed), t); err != nil {
+		return "", err
+	}
+
+	ret, ok := l.Get(-1).(lua.LString)
+	if !ok {
+		return "", fmt.Errorf("test_data returned %s, expected a string", l.Get(-1).Type())
+	}
+	return string(ret), nil
+}
@@ -16,6 +16,7 @@ import (
 type luaState struct {
 	*lua.LState
 	*bytes.Buffer // standard output
+	quit          *quitResult
 }
 
 func newLuaState() luaState {
@@ -80,7 +81,10 @@ func newLuaState() luaState {
 		return 0
 	}))
 
-	return luaState{l, b}
+	q := new(quitResult)
+	openTestlib(l, q)
+
+	return luaState{l, b, q}
 }
 
 func denyFunction(l *lua.LState, name string) {
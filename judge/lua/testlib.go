@@ -0,0 +1,239 @@
+package lua
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// quitResult is a test result reported by ok, fail or partial functions.
+// Comment does not include standard output.
+type quitResult struct {
+	done    bool
+	comment string
+	score   float64
+}
+
+const streamTypeName = "stream"
+
+// tokenStream is a testlib-like reader of whitespace separated tokens.
+type tokenStream struct {
+	name string
+	data string
+	pos  int
+
+	// If set, malformed data is considered a wrong answer instead of a checker failure.
+	strict bool
+}
+
+// openTestlib registers stream type and ok, fail and partial functions.
+func openTestlib(l *lua.LState, q *quitResult) {
+	mt := l.NewTypeMetatable(streamTypeName)
+	l.SetField(mt, "__index", l.SetFuncs(l.NewTable(), map[string]lua.LGFunction{
+		"readToken": func(l *lua.LState) int {
+			s := checkStream(l)
+			l.Push(lua.LString(s.readToken(l, q, "token")))
+			return 1
+		},
+		"readInt": func(l *lua.LState) int {
+			s := checkStream(l)
+			tok := s.readToken(l, q, "integer")
+			n, err := strconv.ParseInt(tok, 10, 64)
+			if err != nil || tok[0] == '+' {
+				s.fail(l, q, "expected integer, found %q", shorten(tok))
+			}
+			if l.GetTop() >= 2 {
+				lo, hi := l.CheckInt64(2), l.OptInt64(3, math.MaxInt64)
+				if n < lo || n > hi {
+					s.fail(l, q, "integer %d violates range [%d, %d]", n, lo, hi)
+				}
+			}
+			l.Push(lua.LNumber(n))
+			return 1
+		},
+		"readNumber": func(l *lua.LState) int {
+			s := checkStream(l)
+			tok := s.readToken(l, q, "number")
+			n, err := strconv.ParseFloat(tok, 64)
+			if err != nil || math.IsInf(n, 0) || math.IsNaN(n) || strings.ContainsFunc(tok, func(r rune) bool {
+				return !strings.ContainsRune("0123456789-.eE", r)
+			}) {
+				s.fail(l, q, "expected number, found %q", shorten(tok))
+			}
+			if l.GetTop() >= 2 {
+				lo, hi := float64(l.CheckNumber(2)), float64(l.OptNumber(3, lua.LNumber(math.Inf(1))))
+				if n < lo || n > hi {
+					s.fail(l, q, "number %g violates range [%g, %g]", n, lo, hi)
+				}
+			}
+			l.Push(lua.LNumber(n))
+			return 1
+		},
+		"readLine": func(l *lua.LState) int {
+			s := checkStream(l)
+			if s.pos >= len(s.data) {
+				s.fail(l, q, "expected line, found end of file")
+			}
+			line, _, _ := strings.Cut(s.data[s.pos:], "\n")
+			s.pos += min(len(line)+1, len(s.data)-s.pos)
+			l.Push(lua.LString(strings.TrimSuffix(line, "\r")))
+			return 1
+		},
+		"eof": func(l *lua.LState) int {
+			s := checkStream(l)
+			l.Push(lua.LBool(s.pos >= len(s.data)))
+			return 1
+		},
+		"seekEOF": func(l *lua.LState) int {
+			s := checkStream(l)
+			s.skipSpace()
+			l.Push(lua.LBool(s.pos >= len(s.data)))
+			return 1
+		},
+		"expectEOF": func(l *lua.LState) int {
+			s := checkStream(l)
+			s.skipSpace()
+			if s.pos < len(s.data) {
+				s.fail(l, q, "expected end of file, found %q", shorten(s.peekToken()))
+			}
+			return 0
+		},
+	}))
+
+	l.SetGlobal("stream", l.NewFunction(func(l *lua.LState) int {
+		pushStream(l, &tokenStream{
+			name: l.OptString(2, streamTypeName),
+			data: l.CheckString(1),
+		})
+		return 1
+	}))
+
+	l.SetGlobal("ok", l.NewFunction(func(l *lua.LState) int {
+		quit(l, q, "", 1)
+		return 0
+	}))
+
+	l.SetGlobal("fail", l.NewFunction(func(l *lua.LState) int {
+		quit(l, q, cmp.Or(formatArgs(l, 1), "test failed"), 0)
+		return 0
+	}))
+
+	l.SetGlobal("partial", l.NewFunction(func(l *lua.LState) int {
+		score := min(max(float64(l.CheckNumber(1)), 0), 1)
+		if math.IsNaN(score) {
+			score = 0
+		}
+		if score >= 1 {
+			quit(l, q, "", 1)
+		}
+		quit(l, q, formatArgs(l, 2), score)
+		return 0
+	}))
+}
+
+// setStreams sets inp and out streams to test input and submission output.
+func setStreams(l *lua.LState, input, output string) {
+	setStream(l, &tokenStream{name: "inp", data: input})
+	setStream(l, &tokenStream{name: "out", data: output, strict: true})
+}
+
+// setStream sets a global variable named after the stream.
+func setStream(l *lua.LState, s *tokenStream) {
+	l.SetGlobal(s.name, pushStream(l, s))
+	l.Pop(1)
+}
+
+func pushStream(l *lua.LState, s *tokenStream) *lua.LUserData {
+	ud := l.NewUserData()
+	ud.Value = s
+	l.SetMetatable(ud, l.GetTypeMetatable(streamTypeName))
+	l.Push(ud)
+	return ud
+}
+
+func checkStream(l *lua.LState) *tokenStream {
+	if s, ok := l.CheckUserData(1).Value.(*tokenStream); ok {
+		return s
+	}
+	l.ArgError(1, "stream expected")
+	return nil
+}
+
+// quit stores a test result and stops the script.
+// Only the first result is kept, so that it can not be overriden by a pcall.
+func quit(l *lua.LState, q *quitResult, comment string, score float64) {
+	if !q.done {
+		*q = quitResult{done: true, comment: comment, score: score}
+	}
+	l.RaiseError("test finished")
+}
+
+// fail reports malformed stream data.
+// Strict streams fail the test, others raise an error.
+func (s *tokenStream) fail(l *lua.LState, q *quitResult, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if s.strict {
+		quit(l, q, "wrong output format: "+msg, 0)
+	}
+	l.RaiseError("%s: %s", s.name, msg)
+}
+
+func (s *tokenStream) skipSpace() {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+func (s *tokenStream) peekToken() string {
+	end := s.pos
+	for end < len(s.data) && !isSpace(s.data[end]) {
+		end++
+	}
+	return s.data[s.pos:end]
+}
+
+func (s *tokenStream) readToken(l *lua.LState, q *quitResult, what string) string {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		s.fail(l, q, "expected %s, found end of file", what)
+	}
+	tok := s.peekToken()
+	s.pos += len(tok)
+	return tok
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// shorten truncates long tokens in comments.
+func shorten(tok string) string {
+	const limit = 32
+	if len(tok) > limit {
+		return tok[:limit] + "..."
+	}
+	return tok
+}
+
+// formatArgs formats arguments starting at index start with string.format.
+// Returns empty string if there are no arguments.
+func formatArgs(l *lua.LState, start int) string {
+	if l.GetTop() < start {
+		return ""
+	}
+	args := make([]lua.LValue, 0, l.GetTop()-start+1)
+	for i := start; i <= l.GetTop(); i++ {
+		args = append(args, l.Get(i))
+	}
+	l.CallByParam(lua.P{
+		Fn:   l.GetField(l.GetGlobal("string"), "format"),
+		NRet: 1,
+	}, args...)
+	res := l.ToString(-1)
+	l.Pop(1)
+	return res
+}
@@ -0,0 +1,111 @@
+package lua
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// Validator contains a parsed lua script to be used for validating generated test input.
+//
+// A function with signature validator(input) must be defined. It will be called with test input
+// and must decide if the input is valid. Return value is interpreted in the same way as in the checker mode of [Checker],
+// except that partial credit is not allowed: any value other than nil, empty string or false rejects the input.
+//
+// Stream inp over test input and functions ok, fail and stream are available, see [Checker].
+// Malformed data in inp rejects the input.
+//
+// Script is sandboxed and global state is wiped between tests.
+//
+// Zero value validator is invalid, use [NewValidator] to construct one. It is safe to copy and use concurrently, because it is immutable.
+type Validator struct {
+	source   string // used for serialization only
+	compiled *lua.FunctionProto
+}
+
+var ErrNotAValidator = errors.New("not a validator")
+
+// NewValidator parses source and creates a new Validator.
+func NewValidator(source string) (Validator, error) {
+	chunks, err := parse.Parse(strings.NewReader(source), "validator.lua")
+	if err != nil {
+		return Validator{}, fmt.Errorf("parse failed: %w", err)
+	}
+
+	f, err := lua.Compile(chunks, "validator.lua")
+	if err != nil {
+		return Validator{}, fmt.Errorf("compilation failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	s := newLuaState()
+	defer s.Close()
+	s.SetContext(ctx)
+
+	s.Push(s.NewFunctionFromProto(f))
+	if err := s.PCall(0, lua.MultRet, nil); err != nil {
+		return Validator{}, fmt.Errorf("initial execution failed: %w", err)
+	}
+
+	if s.GetGlobal("validator").Type() != lua.LTFunction {
+		return Validator{}, fmt.Errorf("%w: validator function must be defined", ErrNotAValidator)
+	}
+
+	return Validator{
+		source:   source,
+		compiled: f,
+	}, nil
+}
+
+// Validate runs the validator. Non nil error means that either input is invalid or validator has failed.
+func (v Validator) Validate(input string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	l := newLuaState()
+	defer l.Close()
+	l.SetContext(ctx)
+
+	l.Push(l.NewFunctionFromProto(v.compiled))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return err
+	}
+
+	setStream(l.LState, &tokenStream{name: "inp", data: input})
+
+	err := l.CallByParam(lua.P{
+		Fn:      l.GetGlobal("validator"),
+		NRet:    1,
+		Protect: true,
+	}, lua.LString(input))
+	if comment, score, ok := quitted(l); ok {
+		if score >= 1 {
+			return nil
+		}
+		return errors.New(cmp.Or(comment, "input rejected"))
+	}
+	if err != nil {
+		return err
+	}
+
+	ret := l.Get(-1)
+	if lua.LVAsBool(ret) && ret != lua.LString("") {
+		if ret == lua.LTrue {
+			return errors.New(cmp.Or(l.Buffer.String(), "input rejected"))
+		}
+		return errors.New(l.Buffer.String() + ret.String())
+	}
+
+	return nil
+}
+
+// Source returns lua source code the validator was created from.
+func (v Validator) Source() string { return v.source }
@@ -0,0 +1,55 @@
+package lua
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	const source = `
+		function validator(input)
+			local n = inp:readInt(1, 10)
+			for i = 1, n do
+				inp:readInt(-100, 100)
+			end
+			inp:expectEOF()
+			if n == 7 then
+				fail("unlucky %d", n)
+			end
+		end
+	`
+
+	v, err := NewValidator(source)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"2\n1 -3\n", true},
+		{"1 100", true},
+		{"0\n", false},
+		{"2\n1\n", false},
+		{"2\n1 2 3\n", false},
+		{"1\n101\n", false},
+		{"7\n1 2 3 4 5 6 7\n", false},
+	}
+
+	for _, tt := range tests {
+		if err := v.Validate(tt.input); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", tt.input, err, tt.valid)
+		}
+	}
+
+	if err := v.Validate("7\n1 2 3 4 5 6 7\n"); err == nil || err.Error() != "unlucky 7" {
+		t.Errorf("err = %v want %q", err, "unlucky 7")
+	}
+}
+
+func TestNotAValidator(t *testing.T) {
+	if _, err := NewValidator(`function checker(input, output) end`); !errors.Is(err, ErrNotAValidator) {
+		t.Errorf("err = %v want %v", err, ErrNotAValidator)
+	}
+}
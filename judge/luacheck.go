@@ -1,6 +1,10 @@
 package judge
 
-import "github.com/TrueHopolok/braincode-/judge/lua"
+import (
+	"errors"
+
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
 
 type luaChecker struct{ lua.Checker }
 
@@ -12,7 +16,19 @@ func NewLuaChecker(source string) (OutputChecker, error) {
 }
 
 func (l *luaChecker) CheckOutput(input string, output string) Verdict {
-	res, err := l.Checker.CheckOutput(input, output)
+	return scoreVerdict(l.Checker.CheckScore(input, output))
+}
+
+func (l *luaChecker) answer(input string) (string, string, error) {
+	res, comment, err := l.Checker.Solution(input)
+	if errors.Is(err, lua.ErrNoSolution) {
+		return "", "", errNoAnswer
+	}
+	return res, comment, err
+}
+
+// scoreVerdict converts result of a lua checker or interactor into a verdict.
+func scoreVerdict(comment string, score float64, err error) Verdict {
 	if err != nil {
 		return Verdict{
 			Status:  StatusCheckerFailed,
@@ -20,10 +36,18 @@ func (l *luaChecker) CheckOutput(input string, output string) Verdict {
 		}
 	}
 
-	if res != "" {
+	if score <= 0 {
 		return Verdict{
 			Status:  StatusWrongAnswer,
-			Comment: res,
+			Comment: comment,
+		}
+	}
+
+	if score < 1 {
+		return Verdict{
+			Status:  StatusPartial,
+			Comment: comment,
+			Score:   score,
 		}
 	}
 
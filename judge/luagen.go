@@ -13,3 +13,60 @@ func NewLuaGenerator(source string) InputGenerator {
 func (g luaGenerator) GenerateInput() ([][]string, error) {
 	return lua.GetTests(string(g))
 }
+
+type seededLuaGenerator struct {
+	Source string
+	Groups []lua.GroupSpec
+}
+
+// NewSeededLuaGenerator creates a new generator from lua source code, that generates declared groups of tests.
+// Every test is reproducible from its seed, see [TestSeed].
+// See [lua.GetSeededTests] for details.
+func NewSeededLuaGenerator(source string, groups []lua.GroupSpec) InputGenerator {
+	return seededLuaGenerator{source, groups}
+}
+
+func (g seededLuaGenerator) GenerateInput() ([][]string, error) {
+	return lua.GetSeededTests(g.Source, g.Groups)
+}
+
+func (g seededLuaGenerator) testSeed(group, test int) (int64, bool) {
+	if group < 0 || group >= len(g.Groups) || test < 0 || test >= g.Groups[group].Tests {
+		return 0, false
+	}
+	return g.Groups[group].TestSeed(test), true
+}
+
+// seededGenerator is implemented by generators with tests reproducible from a seed.
+type seededGenerator interface {
+	testSeed(group, test int) (int64, bool)
+}
+
+// TestSeed returns the seed a test generated by g was generated from, ok is false if it was not generated from a seed.
+// Seed can be used to reproduce the test, see [lua.GetSeededTests].
+func TestSeed(g InputGenerator, group, test int) (seed int64, ok bool) {
+	switch g := g.(type) {
+	case seededGenerator:
+		return g.testSeed(group, test)
+	case *smartGenerator:
+		return TestSeed(g.InputGenerator, group, test)
+	case multiGen:
+		for _, gen := range g {
+			var n int
+			if s, ok := gen.(seededLuaGenerator); ok {
+				n = len(s.Groups)
+			} else {
+				tests, err := gen.GenerateInput()
+				if err != nil {
+					return 0, false
+				}
+				n = len(tests)
+			}
+			if group < n {
+				return TestSeed(gen, group, test)
+			}
+			group -= n
+		}
+	}
+	return 0, false
+}
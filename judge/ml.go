@@ -1,15 +1,32 @@
 package judge
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/TrueHopolok/braincode-/judge/bf"
 	"github.com/TrueHopolok/braincode-/judge/lua"
 	"github.com/TrueHopolok/braincode-/judge/ml"
 )
 
 var errManyCheckers = errors.New("checker / solution defined multiple times")
 
+// DefaultTimeLimit is a wall-clock time limit of a single test for problems created by [NewProblem],
+// unless the document sets its own.
+const DefaultTimeLimit = 2 * time.Second
+
+// MaxTimeLimit is the largest wall-clock time limit of a single test a document may set.
+const MaxTimeLimit = time.Minute
+
+// ReferenceSolution returns brainfunk reference solution of a document: '.reference' block if present,
+// '.solution' block otherwise. Empty string means that document has no reference solution.
+// See [Judge.CheckReference].
+func ReferenceSolution(doc ml.Document) string {
+	return cmp.Or(doc.ReferenceBF, doc.SolutionBF)
+}
+
 func NewProblem(doc ml.Document) (Problem, error) {
 	if doc.Instructions < 1 || doc.Instructions > 100_000 {
 		return Problem{}, errors.New("invalid step constraint")
@@ -20,10 +37,38 @@ func NewProblem(doc ml.Document) (Problem, error) {
 	if doc.Steps < 1 || doc.Steps > 1_000_000 {
 		return Problem{}, errors.New("invalid step constraint")
 	}
+	timeLimit := cmp.Or(time.Duration(doc.Time)*time.Millisecond, DefaultTimeLimit)
+	if doc.Time < 0 || timeLimit > MaxTimeLimit {
+		return Problem{}, errors.New("invalid time constraint")
+	}
+
+	dialect, err := bf.ParseDialect(doc.Dialect)
+	if err != nil {
+		return Problem{}, fmt.Errorf("invalid dialect: %w", err)
+	}
+
+	scoring, err := ParseScoring(doc.Scoring)
+	if err != nil {
+		return Problem{}, fmt.Errorf("invalid scoring: %w", err)
+	}
+
+	comparison, err := ParseComparison(doc.Compare)
+	if err != nil {
+		return Problem{}, fmt.Errorf("invalid comparison: %w", err)
+	}
 
 	var gens []InputGenerator
 
-	if doc.Lua != "" {
+	if doc.Generate != "" {
+		if doc.Lua == "" {
+			return Problem{}, errors.New("seeded test groups require a lua test_data function")
+		}
+		groups, err := lua.ParseGroupSpecs(doc.Generate)
+		if err != nil {
+			return Problem{}, fmt.Errorf("invalid test groups: %w", err)
+		}
+		gens = append(gens, NewSeededLuaGenerator(doc.Lua, groups))
+	} else if doc.Lua != "" {
 		gens = append(gens, NewLuaGenerator(doc.Lua))
 	}
 	if doc.GeneratorBF != "" {
@@ -35,7 +80,32 @@ func NewProblem(doc ml.Document) (Problem, error) {
 	}
 
 	var checker OutputChecker
+	tests := doc.StaticTests()
+	if doc.ExampleTests && len(tests) == len(doc.Tests) {
+		return Problem{}, errors.New("examples are used as tests, but the statement does not have any")
+	}
+	switch {
+	case len(doc.Tests) > 0 && len(gens) > 0:
+		return Problem{}, errors.New("static tests can not be combined with lua or brainfunk generators")
+	case len(tests) > 0 && len(gens) > 0:
+		// only examples, they are judged by the checker of the task like generated tests
+		inputs := make([]string, len(tests[0]))
+		for i, example := range tests[0] {
+			inputs[i] = example.Input
+		}
+		gens = append([]InputGenerator{listGenerator{inputs}}, gens...)
+	case len(tests) > 0:
+		gen, sol, err := staticTests(tests)
+		if err != nil {
+			return Problem{}, err
+		}
+		gens = append(gens, gen)
+		checker = sol
+	}
 	if doc.CheckerBF != "" {
+		if checker != nil {
+			return Problem{}, errManyCheckers
+		}
 		c, err := NewBFChecker(doc.CheckerBF)
 		if err != nil {
 			return Problem{}, fmt.Errorf("provided brainfunk output checker is invalid: %w", err)
@@ -46,16 +116,25 @@ func NewProblem(doc ml.Document) (Problem, error) {
 		if checker != nil {
 			return Problem{}, errManyCheckers
 		}
-		c, err := NewBFSolution(doc.SolutionBF, doc.Instructions, doc.Steps, doc.Memory)
+		c, err := NewBFSolutionDialect(doc.SolutionBF, doc.Instructions, doc.Steps, doc.Memory, dialect)
 		if err != nil {
 			return Problem{}, fmt.Errorf("provided brainfunk solution is invalid: %w", err)
 		}
 		checker = c
 	}
+	var interactor Interactor
 	if doc.Lua != "" {
 		if checker != nil {
 			return Problem{}, errManyCheckers
 		}
+		it, err := NewLuaInteractor(doc.Lua)
+		if err != nil && !errors.Is(err, lua.ErrNotAnInteractor) {
+			return Problem{}, fmt.Errorf("provided lua source is invalid: %w", err)
+		} else if err == nil {
+			interactor = it
+		}
+	}
+	if doc.Lua != "" && interactor == nil {
 		c, err := NewLuaChecker(doc.Lua)
 		if err != nil && !errors.Is(err, lua.ErrNotAChecker) {
 			return Problem{}, fmt.Errorf("provided lua source is invalid: %w", err)
@@ -64,15 +143,57 @@ func NewProblem(doc ml.Document) (Problem, error) {
 		}
 	}
 
-	if checker == nil {
+	if checker == nil && interactor == nil {
 		return Problem{}, errors.New("no checker provided")
 	}
 
+	var validator InputValidator
+	if doc.Validator != "" && doc.ValidatorBF != "" {
+		return Problem{}, errors.New("lua and brainfunk input validators can not be combined")
+	}
+	if doc.Validator != "" {
+		validator, err = NewLuaValidator(doc.Validator)
+		if err != nil {
+			return Problem{}, fmt.Errorf("provided lua input validator is invalid: %w", err)
+		}
+	}
+	if doc.ValidatorBF != "" {
+		validator, err = NewBFValidator(doc.ValidatorBF)
+		if err != nil {
+			return Problem{}, fmt.Errorf("provided brainfunk input validator is invalid: %w", err)
+		}
+	}
+
 	return Problem{
 		InputGenerator: CombineGenerators(gens...),
 		OutputChecker:  checker,
+		Interactor:     interactor,
 		Instructions:   doc.Instructions,
 		Steps:          doc.Steps,
 		Memory:         doc.Memory,
+		Time:           timeLimit,
+		Dialect:        dialect,
+		Scoring:        scoring,
+		Comparison:     comparison,
+		Validator:      validator,
 	}, nil
 }
+
+// staticTests creates a generator and a solution for groups of tests with expected outputs.
+func staticTests(groups [][]ml.Example) (InputGenerator, OutputChecker, error) {
+	tests := make([][]string, len(groups))
+	answers := make(listSolution)
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, nil, fmt.Errorf("static test group %d is empty", i+1)
+		}
+		for j, test := range group {
+			if want, ok := answers[test.Input]; ok && want != test.Output {
+				return nil, nil, fmt.Errorf("static test %d.%d has the same input as another test, but a different output", i+1, j+1)
+			}
+			answers[test.Input] = test.Output
+			tests[i] = append(tests[i], test.Input)
+		}
+	}
+	return listGenerator(tests), answers, nil
+}
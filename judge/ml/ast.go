@@ -22,13 +22,24 @@ type (
 		Instructions int
 		Steps        int
 		Memory       int
+		Time         int    // Wall-clock time limit of a single test in milliseconds, zero for the judge default.
+		Dialect      string // Brainfunk dialect, empty for default. Parsed by the judge.
+		Scoring      string // Scoring scheme, empty for default. Parsed by the judge.
+		Compare      string // Output comparison options, empty for exact comparison. Parsed by the judge.
+		Generate     string // Groups of seeded lua tests, one per line, empty if not used. Parsed by the judge.
 
 		Localizations map[string]*Localizable
 
 		CheckerBF   string
 		SolutionBF  string
 		GeneratorBF string
+		ReferenceBF string // Reference solution used to validate the problem, not used for judging.
+		Validator   string // Lua input validator.
+		ValidatorBF string // Brainfunk input validator, can not be combined with a lua one.
 		Lua         string
+
+		Tests        [][]Example // Groups of static tests with expected outputs, nil if not used.
+		ExampleTests bool        // Examples of the default locale are also used as the first group of tests, see [Document.StaticTests].
 	}
 
 	// Localizable represents all visible localizable content of a document.
@@ -60,6 +71,7 @@ type (
 	Example struct {
 		Input  string
 		Output string
+		Line   int // Line of the block in the parsed document, zero if the example was not parsed.
 	}
 
 	// Image element. String must be a valid URL.
@@ -93,3 +105,38 @@ func (Example) implementsBlock()   {}
 func (Quote) implementsBlock()     {}
 func (Image) implementsBlock()     {}
 func (Math) implementsBlock()      {}
+
+// Examples returns all examples of the locale in the document order, including nested ones.
+func (l *Localizable) Examples() []Example {
+	var res []Example
+	var walk func(blocks []Block)
+	walk = func(blocks []Block) {
+		for _, b := range blocks {
+			switch b := b.(type) {
+			case Example:
+				res = append(res, b)
+			case Quote:
+				walk(b)
+			case List:
+				for _, item := range b.Items {
+					walk(item)
+				}
+			}
+		}
+	}
+	walk(l.Blocks)
+	return res
+}
+
+// StaticTests returns groups of static tests of the document.
+// If [Document.ExampleTests] is set, examples of the default locale are the first group.
+func (d Document) StaticTests() [][]Example {
+	if !d.ExampleTests || d.Localizations[""] == nil {
+		return d.Tests
+	}
+	examples := d.Localizations[""].Examples()
+	if len(examples) == 0 {
+		return d.Tests
+	}
+	return append([][]Example{examples}, d.Tests...)
+}
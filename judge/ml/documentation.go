@@ -70,8 +70,9 @@
 //
 // These blocks will not be shown to problem solver directly. Instead they are used to change some
 // information about a task. Each of the blocks may be specified only once, with the exception of
-// '.task', which can be specified once per locale. Meta blocks may appear anywhere in the document
-// tree (except for localization blocks), but preferably should be placed on the top level.
+// '.task', which can be specified once per locale, and '.test' and '.group'. Meta blocks may appear
+// anywhere in the document tree (except for localization blocks), but preferably should be placed on
+// the top level.
 //
 // '.task' - title of the solution. Must be plain text, does not support formatting.
 //
@@ -81,6 +82,39 @@
 //
 // '.memory' - maximum number of runtime bytes a solution can allocate.
 //
+// '.time' - maximum wall-clock time of a single test in milliseconds. Default is 2000.
+//
+// '.dialect' - brainfunk dialect of a solution, a list of key=value pairs: cell width
+// (cell=8|16|32), overflow behavior (overflow=wrap|error), end of input behavior
+// (eof=error|unchanged|zero|minus-one) and tape topology (tape=right|bounded|circular|infinite
+// with size=N for bounded and circular tapes). Omitted keys keep their defaults, which are listed first.
+//
+// '.scoring' - how test groups are scored: 'min' (a group earns the minimum credit of its tests) or
+// 'sum' (a group earns the average credit of its tests), optionally followed by points of every group,
+// for example 'min 20 30 50'. Without points groups are weighted by number of tests. Default is 'min'.
+//
+// '.compare' - how output is compared with a reference solution, a list of options: 'trailing-space'
+// (ignore trailing whitespace and empty lines), 'tokens' (compare whitespace separated tokens),
+// 'ignore-case', 'line-endings' (treat CRLF as LF) and 'epsilon=X' (numbers may differ by X, implies
+// 'tokens'). Default is exact comparison.
+//
+// '.generate' - groups of tests generated by the lua test_data(seed, params) function, one group per
+// line. Each line is a list of key=value pairs: 'tests' (number of tests in the group), 'seed' (seed of
+// the first test, following tests use consecutive seeds) and any named parameters passed to test_data,
+// for example 'tests=10 seed=100 n=1000'. Every test can be reproduced from its seed.
+//
+// '.test' - static test with an expected output. Must contain one '.input' and one '.output' block,
+// like '.example'. A test outside of a group forms a group of its own. Static tests cannot be combined
+// with lua or brainfunk generators. Multi-line input and output end with a newline, empty lines must
+// be escaped with '!'.
+//
+// '.group' - group of static tests. Must contain one or more '.test' blocks.
+//
+// '.examples' - how examples are used: 'statement' (only shown to problem solver) or 'tests' (examples
+// of the default locale are also used as the first group of tests). Default is 'statement'. With static
+// tests outputs of examples are expected answers, with lua or brainfunk generators examples are judged
+// by the checker of the task, like generated tests.
+//
 // '.[locale]' - mark block for localization. May appear only on the top level (cannot be nested
 // inside other blocks). Only locales defined in [KnownLocales] are supported. All blocks outside of
 // a localization block belong to a default locale (empty string). Only document blocks and '.task' blocks
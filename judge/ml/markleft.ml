@@ -76,13 +76,53 @@ contain ~C[.input] and ~C[.output] blocks which act like ~C[.code] blocks.
 .paragraph
 These blocks will not be shown to problem solver directly. Instead they are used to change some
 information about a task. Each of the blocks may be specified only once, with the exception of
-~C[.task], which can be specified once per locale. Meta blocks may appear anywhere in the document
+~C[.task], which can be specified once per locale, and ~C[.test] and ~C[.group]. Meta blocks may appear anywhere in the document
 tree (except for ~I[localization blocks]), but preferably should be placed on the top level.
 ..
 .paragraph = ~C[.task] - title of the task. Must be plain text, does not support formatting.
 .paragraph = ~C[.instructions] - maximum number of instructions submitted solution can have.
 .paragraph = ~C[.steps] - maximum number of runtime steps a solution can take.
 .paragraph = ~C[.memory] - maximum number of runtime bytes a solution can allocate.
+.paragraph = ~C[.time] - maximum wall-clock time of a single test in milliseconds. Default is 2000.
+.paragraph
+~C[.dialect] - brainfunk dialect of a solution, a list of ~C[key=value] pairs: cell width
+(~C[cell=8|16|32]), overflow behavior (~C[overflow=wrap|error]), end of input behavior
+(~C[eof=error|unchanged|zero|minus-one]) and tape topology (~C[tape=right|bounded|circular|infinite]
+with ~C[size=N] for bounded and circular tapes). Omitted keys keep their defaults, which are listed
+first.
+..
+.paragraph
+~C[.scoring] - how test groups are scored: ~C[min] (a group earns the minimum credit of its tests)
+or ~C[sum] (a group earns the average credit of its tests), optionally followed by points of every
+group, for example ~C[min 20 30 50]. Without points groups are weighted by number of tests. Default
+is ~C[min].
+..
+.paragraph
+~C[.compare] - how output is compared with a reference solution, a list of options:
+~C[trailing-space] (ignore trailing whitespace and empty lines), ~C[tokens] (compare whitespace
+separated tokens), ~C[ignore-case], ~C[line-endings] (treat CRLF as LF) and ~C[epsilon=X] (numbers
+may differ by X, implies ~C[tokens]). Default is exact comparison.
+..
+.paragraph
+~C[.generate] - groups of tests generated by the lua ~C[test_data(seed, params)] function, one
+group per line. Each line is a list of key=value pairs: ~C[tests] (number of tests in the group),
+~C[seed] (seed of the first test, following tests use consecutive seeds) and any named parameters
+passed to ~C[test_data], for example ~C[tests=10 seed=100 n=1000]. Every test can be reproduced from
+its seed.
+..
+.paragraph
+~C[.test] - static test with an expected output. Must contain one ~C[.input] and one ~C[.output]
+block, like ~C[.example]. A test outside of a group forms a group of its own. Static tests cannot be
+combined with lua or brainfunk generators. Multi-line input and output end with a newline, empty
+lines must be escaped with ~C[!].
+..
+.paragraph = ~C[.group] - group of static tests. Must contain one or more ~C[.test] blocks.
+.paragraph
+~C[.examples] - how examples are used: ~C[statement] (only shown to problem solver) or ~C[tests]
+(examples of the default locale are also used as the first group of tests). Default is ~C[statement].
+With static tests outputs of examples are expected answers, with lua or brainfunk generators examples are
+judged by the checker of the task, like generated tests.
+..
 .paragraph
 ~C[.[locale~]] - mark block for localization. May appear only on the top level (cannot be nested
 inside other blocks). Currently only ~C[.ru] and ~C[.en] locales are supported. All blocks outside
@@ -158,13 +198,53 @@ contain ~C[.input] and ~C[.output] blocks which act like ~C[.code] blocks.
 .paragraph
 These blocks will not be shown to problem solver directly. Instead they are used to change some
 information about a task. Each of the blocks may be specified only once, with the exception of
-~C[.task], which can be specified once per locale. Meta blocks may appear anywhere in the document
+~C[.task], which can be specified once per locale, and ~C[.test] and ~C[.group]. Meta blocks may appear anywhere in the document
 tree (except for ~I[localization blocks]), but preferably should be placed on the top level.
 ..
 .paragraph = ~C[.task] - title of the task. Must be plain text, does not support formatting.
 .paragraph = ~C[.instructions] - maximum number of instructions submitted solution can have.
 .paragraph = ~C[.steps] - maximum number of runtime steps a solution can take.
 .paragraph = ~C[.memory] - maximum number of runtime bytes a solution can allocate.
+.paragraph = ~C[.time] - maximum wall-clock time of a single test in milliseconds. Default is 2000.
+.paragraph
+~C[.dialect] - brainfunk dialect of a solution, a list of ~C[key=value] pairs: cell width
+(~C[cell=8|16|32]), overflow behavior (~C[overflow=wrap|error]), end of input behavior
+(~C[eof=error|unchanged|zero|minus-one]) and tape topology (~C[tape=right|bounded|circular|infinite]
+with ~C[size=N] for bounded and circular tapes). Omitted keys keep their defaults, which are listed
+first.
+..
+.paragraph
+~C[.scoring] - how test groups are scored: ~C[min] (a group earns the minimum credit of its tests)
+or ~C[sum] (a group earns the average credit of its tests), optionally followed by points of every
+group, for example ~C[min 20 30 50]. Without points groups are weighted by number of tests. Default
+is ~C[min].
+..
+.paragraph
+~C[.compare] - how output is compared with a reference solution, a list of options:
+~C[trailing-space] (ignore trailing whitespace and empty lines), ~C[tokens] (compare whitespace
+separated tokens), ~C[ignore-case], ~C[line-endings] (treat CRLF as LF) and ~C[epsilon=X] (numbers
+may differ by X, implies ~C[tokens]). Default is exact comparison.
+..
+.paragraph
+~C[.generate] - groups of tests generated by the lua ~C[test_data(seed, params)] function, one
+group per line. Each line is a list of key=value pairs: ~C[tests] (number of tests in the group),
+~C[seed] (seed of the first test, following tests use consecutive seeds) and any named parameters
+passed to ~C[test_data], for example ~C[tests=10 seed=100 n=1000]. Every test can be reproduced from
+its seed.
+..
+.paragraph
+~C[.test] - static test with an expected output. Must contain one ~C[.input] and one ~C[.output]
+block, like ~C[.example]. A test outside of a group forms a group of its own. Static tests cannot be
+combined with lua or brainfunk generators. Multi-line input and output end with a newline, empty
+lines must be escaped with ~C[!].
+..
+.paragraph = ~C[.group] - group of static tests. Must contain one or more ~C[.test] blocks.
+.paragraph
+~C[.examples] - how examples are used: ~C[statement] (only shown to problem solver) or ~C[tests]
+(examples of the default locale are also used as the first group of tests). Default is ~C[statement].
+With static tests outputs of examples are expected answers, with lua or brainfunk generators examples are
+judged by the checker of the task, like generated tests.
+..
 .paragraph
 ~C[.[locale~]] - mark block for localization. May appear only on the top level (cannot be nested
 inside other blocks). Currently only ~C[.ru] and ~C[.en] locales are supported. All blocks outside
@@ -268,7 +348,7 @@ times and will be equivalent to concatenation of all localization blocks of the
 нужны чтобы настраивать те или иные параметры. Каждый
 мета блок может использоваться только один раз, за
 исключением ~C[.task], который может быть уникальным для
-каждой локализации. Мета блоки могут быть где угодно в
+каждой локализации, а также ~C[.test] и ~C[.group]. Мета блоки могут быть где угодно в
 документе (за исключением ~I[блоков локализации]), но
 обычно они находятся на верхнем уровне.
 ..
@@ -289,6 +369,68 @@ times and will be equivalent to concatenation of all localization blocks of the
 которое может использовать решение.
 ..
 .paragraph
+~C[.time] - максимальное время выполнения одного теста в
+миллисекундах. По умолчанию 2000.
+..
+.paragraph
+~C[.dialect] - диалект brainfunk для решения, список пар
+~C[ключ=значение]: размер ячейки (~C[cell=8|16|32]), поведение
+при переполнении (~C[overflow=wrap|error]), поведение при
+конце ввода (~C[eof=error|unchanged|zero|minus-one]) и
+топология ленты (~C[tape=right|bounded|circular|infinite], с
+~C[size=N] для ограниченной и кольцевой ленты).
+Пропущенные ключи принимают значения по умолчанию,
+которые указаны первыми.
+..
+.paragraph
+~C[.scoring] - способ подсчёта баллов за группы тестов:
+~C[min] (группа получает минимальный балл среди своих
+тестов) или ~C[sum] (группа получает средний балл своих
+тестов), после чего могут следовать баллы каждой группы,
+например ~C[min 20 30 50]. Без баллов вес группы равен
+числу тестов в ней. По умолчанию ~C[min].
+..
+.paragraph
+~C[.compare] - способ сравнения вывода с эталонным решением,
+список опций: ~C[trailing-space] (игнорировать пробелы в
+конце строк и пустые строки в конце), ~C[tokens] (сравнивать
+разделённые пробелами слова), ~C[ignore-case],
+~C[line-endings] (считать CRLF равным LF) и ~C[epsilon=X]
+(числа могут отличаться на X, включает ~C[tokens]). По
+умолчанию вывод должен совпадать в точности.
+..
+.paragraph
+~C[.generate] - группы тестов, создаваемых lua функцией
+~C[test_data(seed, params)], по одной группе на строку.
+Каждая строка - список пар key=value: ~C[tests] (число тестов
+в группе), ~C[seed] (сид первого теста, следующие тесты
+используют последовательные сиды) и любые именованные
+параметры для ~C[test_data], например
+~C[tests=10 seed=100 n=1000]. Любой тест можно воспроизвести
+по его сиду.
+..
+.paragraph
+~C[.test] - статический тест с ожидаемым выводом. Должен
+содержать один блок ~C[.input] и один блок ~C[.output], как
+~C[.example]. Тест вне группы образует отдельную группу.
+Статические тесты нельзя сочетать с lua или brainfunk
+генераторами. Многострочные ввод и вывод заканчиваются
+переводом строки, пустые строки нужно экранировать ~C[!].
+..
+.paragraph
+~C[.group] - группа статических тестов. Должна содержать
+один или несколько блоков ~C[.test].
+..
+.paragraph
+~C[.examples] - как используются примеры: ~C[statement]
+(только показываются участнику) или ~C[tests] (примеры
+локализации по умолчанию также используются как первая
+группа тестов). По умолчанию ~C[statement]. Вместе со
+статическими тестами выводы примеров являются ожидаемыми
+ответами, а вместе с генераторами на lua или brainfunk
+примеры проверяются чекером задачи, как и сгенерированные тесты.
+..
+.paragraph
 ~C[.[locale~]] - маркер локализации. Должен быть на верхнем
 уровне (не может быть вложен в другие блоки). На данный
 момент поддерживаются только локализации ~C[.ru] и ~C[.en].
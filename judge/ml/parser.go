@@ -28,6 +28,14 @@ const (
 	blockInstructions
 	blockSteps
 	blockMemory
+	blockTime
+	blockDialect
+	blockScoring
+	blockCompare
+	blockGenerate
+	blockExamples
+	blockTest
+	blockGroup
 	blockSection
 	blockParagraph
 	blockQuote
@@ -38,6 +46,9 @@ const (
 	blockImage
 	blockChecker
 	blockSolution
+	blockReference
+	blockValidator
+	blockValidatorBF
 	blockGenerator
 	blockLua
 	blockOrdered
@@ -52,6 +63,14 @@ var blockToString = map[blockType]string{
 	blockInstructions: "instructions",
 	blockSteps:        "steps",
 	blockMemory:       "memory",
+	blockTime:         "time",
+	blockDialect:      "dialect",
+	blockScoring:      "scoring",
+	blockCompare:      "compare",
+	blockGenerate:     "generate",
+	blockExamples:     "examples",
+	blockTest:         "test",
+	blockGroup:        "group",
 	blockSection:      "section",
 	blockParagraph:    "paragraph",
 	blockQuote:        "quote",
@@ -60,6 +79,9 @@ var blockToString = map[blockType]string{
 	blockImage:        "image",
 	blockChecker:      "checker",
 	blockSolution:     "solution",
+	blockReference:    "reference",
+	blockValidator:    "validator",
+	blockValidatorBF:  "bfvalidator",
 	blockGenerator:    "generator",
 	blockLua:          "lua",
 	blockOrdered:      "ordered",
@@ -74,6 +96,14 @@ var stringToBlock = map[string]blockType{
 	"instructions": blockInstructions,
 	"steps":        blockSteps,
 	"memory":       blockMemory,
+	"time":         blockTime,
+	"dialect":      blockDialect,
+	"scoring":      blockScoring,
+	"compare":      blockCompare,
+	"generate":     blockGenerate,
+	"examples":     blockExamples,
+	"test":         blockTest,
+	"group":        blockGroup,
 	"section":      blockSection,
 	"paragraph":    blockParagraph,
 	"quote":        blockQuote,
@@ -84,6 +114,9 @@ var stringToBlock = map[string]blockType{
 	"image":        blockImage,
 	"checker":      blockChecker,
 	"solution":     blockSolution,
+	"reference":    blockReference,
+	"validator":    blockValidator,
+	"bfvalidator":  blockValidatorBF,
 	"generator":    blockGenerator,
 	"lua":          blockLua,
 	"ordered":      blockOrdered,
@@ -175,6 +208,77 @@ func init() {
 			return nil
 		},
 
+		blockTime: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(intProperty(pctx.Buf(), &pctx.Doc.Time, b))
+			return nil
+		},
+
+		blockDialect: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Dialect, b))
+			pctx.Doc.Dialect = inline(pctx.Doc.Dialect)
+			return nil
+		},
+
+		blockScoring: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Scoring, b))
+			pctx.Doc.Scoring = inline(pctx.Doc.Scoring)
+			return nil
+		},
+
+		blockCompare: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Compare, b))
+			pctx.Doc.Compare = inline(pctx.Doc.Compare)
+			return nil
+		},
+
+		blockGenerate: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Generate, b))
+			return nil
+		},
+
+		blockExamples: func(pctx *parserContext, b *rawBlock) Block {
+			var value string
+			if err := stringProperty(pctx.Buf(), &value, b); err != nil {
+				pctx.PushErr(err)
+				return nil
+			}
+			switch inline(value) {
+			case "statement":
+			case "tests":
+				if pctx.Doc.ExampleTests {
+					pctx.PushErr(errors.New("duplicate definition"))
+				}
+				pctx.Doc.ExampleTests = true
+			default:
+				pctx.PushErr(fmt.Errorf("unknown value %q, want statement or tests", inline(value)))
+			}
+			return nil
+		},
+
+		blockTest: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.Doc.Tests = append(pctx.Doc.Tests, []Example{parseExample(pctx, b)})
+			return nil
+		},
+
+		blockGroup: func(pctx *parserContext, b *rawBlock) Block {
+			var group []Example
+			for _, c := range b.children {
+				pop := pctx.PushPath(c)
+				if c.kind == blockTest {
+					group = append(group, parseExample(pctx, c))
+				} else {
+					pctx.PushErr(errors.New("unsuported block inside group"))
+				}
+				pop()
+			}
+			if len(group) == 0 {
+				pctx.PushErr(errors.New("group must contain at least one test"))
+				return nil
+			}
+			pctx.Doc.Tests = append(pctx.Doc.Tests, group)
+			return nil
+		},
+
 		blockSection: func(pctx *parserContext, b *rawBlock) Block {
 			data, err := textOnly(pctx.Buf(), b)
 			if err != nil {
@@ -246,6 +350,21 @@ func init() {
 			return nil
 		},
 
+		blockReference: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.ReferenceBF, b))
+			return nil
+		},
+
+		blockValidator: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Validator, b))
+			return nil
+		},
+
+		blockValidatorBF: func(pctx *parserContext, b *rawBlock) Block {
+			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.ValidatorBF, b))
+			return nil
+		},
+
 		blockLua: func(pctx *parserContext, b *rawBlock) Block {
 			pctx.PushErr(stringProperty(pctx.Buf(), &pctx.Doc.Lua, b))
 			return nil
@@ -255,61 +374,15 @@ func init() {
 		blockUnordered: parseList(false),
 
 		blockExampleInput: func(pctx *parserContext, b *rawBlock) Block {
-			pctx.PushErr(errors.New("input block cannot be used outside of example block"))
+			pctx.PushErr(errors.New("input block cannot be used outside of example or test block"))
 			return nil
 		},
 		blockExampleOutput: func(pctx *parserContext, b *rawBlock) Block {
-			pctx.PushErr(errors.New("output block cannot be used outside of example block"))
+			pctx.PushErr(errors.New("output block cannot be used outside of example or test block"))
 			return nil
 		},
 		blockExample: func(pctx *parserContext, b *rawBlock) Block {
-			var input, output string
-			var inputLine, outputLine int
-			for _, c := range b.children {
-				pop := pctx.PushPath(c)
-				switch c.kind {
-				case blockExampleInput:
-					if inputLine != 0 {
-						pctx.PushErr(fmt.Errorf("duplicate input block (first defined at line %d)",
-							inputLine))
-					} else {
-						s, err := textOnly(pctx.Buf(), c)
-						if err != nil {
-							pctx.PushErr(err)
-						}
-						input = s
-						inputLine = c.line
-					}
-				case blockExampleOutput:
-					if outputLine != 0 {
-						pctx.PushErr(fmt.Errorf("duplicate output block (first defined at line %d)",
-							outputLine))
-					} else {
-						s, err := textOnly(pctx.Buf(), c)
-						if err != nil {
-							pctx.PushErr(err)
-						}
-						output = s
-						outputLine = c.line
-					}
-				default:
-					pctx.PushErr(errors.New("unsuported block inside example"))
-				}
-				pop()
-			}
-
-			if inputLine == 0 {
-				pctx.PushErr(errors.New("missing input block"))
-			}
-			if outputLine == 0 {
-				pctx.PushErr(errors.New("missing output block"))
-			}
-
-			return Example{
-				Input:  input,
-				Output: output,
-			}
-
+			return parseExample(pctx, b)
 		},
 		blockMath: func(pctx *parserContext, b *rawBlock) Block {
 			data, err := textOnly(pctx.Buf(), b)
@@ -322,6 +395,57 @@ func init() {
 	}
 }
 
+// parseExample parses an example or a test block, which must contain one input and one output block.
+func parseExample(pctx *parserContext, b *rawBlock) Example {
+	var input, output string
+	var inputLine, outputLine int
+	for _, c := range b.children {
+		pop := pctx.PushPath(c)
+		switch c.kind {
+		case blockExampleInput:
+			if inputLine != 0 {
+				pctx.PushErr(fmt.Errorf("duplicate input block (first defined at line %d)",
+					inputLine))
+			} else {
+				s, err := textOnly(pctx.Buf(), c)
+				if err != nil {
+					pctx.PushErr(err)
+				}
+				input = s
+				inputLine = c.line
+			}
+		case blockExampleOutput:
+			if outputLine != 0 {
+				pctx.PushErr(fmt.Errorf("duplicate output block (first defined at line %d)",
+					outputLine))
+			} else {
+				s, err := textOnly(pctx.Buf(), c)
+				if err != nil {
+					pctx.PushErr(err)
+				}
+				output = s
+				outputLine = c.line
+			}
+		default:
+			pctx.PushErr(fmt.Errorf("unsuported block inside %s", blockToString[b.kind]))
+		}
+		pop()
+	}
+
+	if inputLine == 0 {
+		pctx.PushErr(errors.New("missing input block"))
+	}
+	if outputLine == 0 {
+		pctx.PushErr(errors.New("missing output block"))
+	}
+
+	return Example{
+		Input:  input,
+		Output: output,
+		Line:   b.line,
+	}
+}
+
 func parseList(ordered bool) blockParser {
 	return func(pctx *parserContext, b *rawBlock) Block {
 		var data []ListItem
@@ -19,6 +19,32 @@ Hello, this is a paragraph.
 ..
 `
 
+const doc2 = "" +
+	`
+.task = Tests
+.examples = tests
+.example
+.input = 1 2
+.output = 3
+..
+.test
+.input = 2 2
+.output = 4
+..
+.group
+.test
+.input
+5 5
+..
+.output = 10
+..
+.test
+.input = 0 0
+.output = 0
+..
+..
+`
+
 func TestParse(t *testing.T) {
 	tests := []struct {
 		name string // description of this test case
@@ -37,6 +63,25 @@ func TestParse(t *testing.T) {
 					},
 				}},
 		}, false},
+		{"tests", strings.NewReader(doc2), Document{
+			Localizations: map[string]*Localizable{
+				"": {
+					Name: "Tests",
+					Blocks: []Block{
+						Example{Input: "1 2", Output: "3", Line: 4},
+					},
+				}},
+			Tests: [][]Example{
+				{{Input: "2 2", Output: "4", Line: 8}},
+				{{Input: "5 5\n", Output: "10", Line: 13}, {Input: "0 0", Output: "0", Line: 19}},
+			},
+			ExampleTests: true,
+		}, false},
+		{"empty group", strings.NewReader(".task = X\n.group\n..\n"), Document{}, true},
+		{"test without output", strings.NewReader(".task = X\n.test\n.input = 1\n..\n"), Document{}, true},
+		{"input outside test", strings.NewReader(".task = X\n.input = 1\n"), Document{}, true},
+		{"paragraph in group", strings.NewReader(".task = X\n.group\n.paragraph = x\n..\n"), Document{}, true},
+		{"unknown examples", strings.NewReader(".task = X\n.examples = hidden\n"), Document{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
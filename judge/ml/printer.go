@@ -175,11 +175,16 @@ func renderRich(t RichText) string {
 func escape(s string) string {
 	b := new(strings.Builder)
 	for line := range strings.Lines(s) {
-		if len(line) > 0 && line[0] == '!' || line[0] == '.' {
+		if line[0] == '!' || line[0] == '.' || line[0] == '\n' {
+			// empty lines are skipped by the parser, unless escaped
 			b.WriteByte('!')
 		}
 		b.WriteString(line)
 	}
+	if strings.HasSuffix(s, "\n") {
+		// last line is empty
+		b.WriteByte('!')
+	}
 	return b.String()
 }
 
@@ -225,6 +230,21 @@ func (d Document) WriteSyntax(w io.Writer) (err error) {
 	printf(".instructions = %d\n", d.Instructions)
 	printf(".steps = %d\n", d.Steps)
 	printf(".memory = %d\n", d.Memory)
+	if d.Time != 0 {
+		printf(".time = %d\n", d.Time)
+	}
+	if d.Dialect != "" {
+		printf(".dialect = %s\n", inline(d.Dialect))
+	}
+	if d.Scoring != "" {
+		printf(".scoring = %s\n", inline(d.Scoring))
+	}
+	if d.Compare != "" {
+		printf(".compare = %s\n", inline(d.Compare))
+	}
+	if d.ExampleTests {
+		printf(".examples = tests\n")
+	}
 
 	first := true
 
@@ -239,10 +259,7 @@ func (d Document) WriteSyntax(w io.Writer) (err error) {
 			printBlock(printf, "code", string(b))
 
 		case Example:
-			printf(".example\n")
-			printBlock(printf, "input", b.Input)
-			printBlock(printf, "output", b.Output)
-			printf("..\n")
+			printExample(printf, "example", b)
 
 		case Image:
 			u, err := url.Parse(string(b))
@@ -315,7 +332,7 @@ func (d Document) WriteSyntax(w io.Writer) (err error) {
 		printf("..\n")
 	}
 
-	if d.CheckerBF != "" || d.SolutionBF != "" || d.GeneratorBF != "" || d.Lua != "" {
+	if d.CheckerBF != "" || d.SolutionBF != "" || d.GeneratorBF != "" || d.ReferenceBF != "" || d.Validator != "" || d.ValidatorBF != "" || d.Generate != "" || d.Lua != "" || len(d.Tests) > 0 {
 		printf("\n")
 	}
 
@@ -328,9 +345,32 @@ func (d Document) WriteSyntax(w io.Writer) (err error) {
 	if d.GeneratorBF != "" {
 		printBlock(printf, "generator", d.GeneratorBF)
 	}
+	if d.ReferenceBF != "" {
+		printBlock(printf, "reference", d.ReferenceBF)
+	}
+	if d.Validator != "" {
+		printBlock(printf, "validator", d.Validator)
+	}
+	if d.ValidatorBF != "" {
+		printBlock(printf, "bfvalidator", d.ValidatorBF)
+	}
+	if d.Generate != "" {
+		printBlock(printf, "generate", d.Generate)
+	}
 	if d.Lua != "" {
 		printBlock(printf, "lua", d.Lua)
 	}
+	for _, group := range d.Tests {
+		if len(group) == 1 {
+			printExample(printf, "test", group[0])
+			continue
+		}
+		printf(".group\n")
+		for _, test := range group {
+			printExample(printf, "test", test)
+		}
+		printf("..\n")
+	}
 
 	return bw.Flush()
 }
@@ -354,6 +394,13 @@ func printContainer(printf func(string, ...any), render func(Block), name string
 	printf("..\n")
 }
 
+func printExample(printf func(string, ...any), name string, e Example) {
+	printf(".%s\n", name)
+	printBlock(printf, "input", e.Input)
+	printBlock(printf, "output", e.Output)
+	printf("..\n")
+}
+
 func printBlock(printf func(string, ...any), name string, data string) {
 	if strings.IndexByte(data, '\n') == -1 {
 		printf(".%s = %s\n", name, strings.TrimSpace(data))
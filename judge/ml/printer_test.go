@@ -1,6 +1,10 @@
 package ml
 
-import "testing"
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
 
 func Test_renderRich(t *testing.T) {
 	tests := []struct {
@@ -38,3 +42,46 @@ func Test_renderRich(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteSyntax_tests(t *testing.T) {
+	want := Document{
+		Localizations: map[string]*Localizable{
+			"": {
+				Name: "Tests",
+				Blocks: []Block{
+					Example{Input: "1 2\n", Output: "3\n"},
+				},
+			}},
+		Tests: [][]Example{
+			{{Input: "a\n\nb\n", Output: "!\n.\n"}},
+			{{Input: "", Output: "x\n\n"}, {Input: "y", Output: "y"}},
+		},
+		ExampleTests: true,
+	}
+
+	b := new(bytes.Buffer)
+	if err := want.WriteSyntax(b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(b)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	// lines are not a part of the syntax
+	for i, b := range got.Localizations[""].Blocks {
+		if e, ok := b.(Example); ok {
+			e.Line = 0
+			got.Localizations[""].Blocks[i] = e
+		}
+	}
+	for _, group := range got.Tests {
+		for i := range group {
+			group[i].Line = 0
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(WriteSyntax()) = %+v, want %+v", got, want)
+	}
+}
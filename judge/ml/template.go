@@ -29,6 +29,7 @@ type TemplatableDocument struct {
 	Instructions int
 	Steps        int
 	Memory       int
+	Dialect      string // Empty for the default dialect.
 
 	Blocks []Block
 
@@ -65,6 +66,7 @@ const (
 	HTMLClassInfoInstructions
 	HTMLClassInfoSteps
 	HTMLClassInfoMemory
+	HTMLClassInfoDialect
 
 	// inlines
 
@@ -104,6 +106,7 @@ func (m *HTMLClassMap) InfoBlock() string        { return m[HTMLClassInfoBlock]
 func (m *HTMLClassMap) InfoInstructions() string { return m[HTMLClassInfoInstructions] }
 func (m *HTMLClassMap) InfoSteps() string        { return m[HTMLClassInfoSteps] }
 func (m *HTMLClassMap) InfoMemory() string       { return m[HTMLClassInfoMemory] }
+func (m *HTMLClassMap) InfoDialect() string      { return m[HTMLClassInfoDialect] }
 func (m *HTMLClassMap) SpanLink() string         { return m[HTMLClassSpanLink] }
 func (m *HTMLClassMap) SpanBold() string         { return m[HTMLClassSpanBold] }
 func (m *HTMLClassMap) SpanItalic() string       { return m[HTMLClassSpanItalic] }
@@ -156,6 +159,7 @@ var DefaultClassMap = HTMLClassMap{
 	HTMLClassInfoInstructions: "infoInstructions",
 	HTMLClassInfoSteps:        "infoSteps",
 	HTMLClassInfoMemory:       "infoMemory",
+	HTMLClassInfoDialect:      "infoDialect",
 	HTMLClassSpanLink:         "spanLink",
 	HTMLClassSpanBold:         "spanBold",
 	HTMLClassSpanItalic:       "spanItalic",
@@ -228,6 +232,7 @@ func (d Document) Templatable(locale string) TemplatableDocument {
 		Instructions: d.Instructions,
 		Steps:        d.Steps,
 		Memory:       d.Memory,
+		Dialect:      d.Dialect,
 		Blocks:       loc.Blocks,
 		TemplateContext: TemplateContext{
 			CM: &DefaultClassMap,
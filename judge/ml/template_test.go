@@ -73,6 +73,7 @@ bla bla bla
 					Example{
 						Input:  "bla bla bla\n",
 						Output: "hello world!",
+						Line:   27,
 					},
 				},
 			},
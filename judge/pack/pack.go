@@ -0,0 +1,335 @@
+// Package pack reads and writes problem packages.
+//
+// Problem package is a directory or a zip archive, that keeps a markleft task and its sources in separate files,
+// so that tasks can be kept in version control and moved between servers:
+//
+//	statement.ml              markleft statement with limits and settings, required
+//	checker.lua               lua script, same as the '.lua' block
+//	generator.bf              brainfunk generator, same as the '.generator' block
+//	checker.bf                brainfunk checker, same as the '.checker' block
+//	solution.bf               brainfunk solution, same as the '.solution' block
+//	reference.bf              brainfunk reference solution, same as the '.reference' block
+//	validator.lua             lua input validator, same as the '.validator' block
+//	validator.bf              brainfunk input validator, same as the '.bfvalidator' block
+//	tests/G.T.in              input of test T of group G, both numbered from 1
+//	tests/G.T.out             expected output of the same test, same as the '.test' and '.group' blocks
+//
+// Every source and static tests may be either inlined into statement.ml or stored in their own files, but not both.
+// Zip archives may also contain a single top level directory with the package.
+//
+//go:generate go tool github.com/princjef/gomarkdoc/cmd/gomarkdoc -o documentation.md
+package pack
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+// Names of files in a package.
+const (
+	StatementFile    = "statement.ml"
+	LuaFile          = "checker.lua"
+	GeneratorFile    = "generator.bf"
+	CheckerFile      = "checker.bf"
+	SolutionFile     = "solution.bf"
+	ReferenceFile    = "reference.bf"
+	LuaValidatorFile = "validator.lua"
+	BFValidatorFile  = "validator.bf"
+	TestsDir         = "tests"
+)
+
+// MaxSize is a maximum total size of files read from a package.
+const MaxSize = 64 << 20
+
+var errTooLarge = fmt.Errorf("package is larger than %d bytes", MaxSize)
+
+// sources returns document fields that can be stored in separate files, keyed by file name.
+func sources(doc *ml.Document) map[string]*string {
+	return map[string]*string{
+		LuaFile:          &doc.Lua,
+		GeneratorFile:    &doc.GeneratorBF,
+		CheckerFile:      &doc.CheckerBF,
+		SolutionFile:     &doc.SolutionBF,
+		ReferenceFile:    &doc.ReferenceBF,
+		LuaValidatorFile: &doc.Validator,
+		BFValidatorFile:  &doc.ValidatorBF,
+	}
+}
+
+// reader reads files of a package, limiting their total size.
+type reader struct {
+	fsys   fs.FS
+	budget int64
+}
+
+// read returns contents of a file, ok is false if the file does not exist.
+func (r *reader) read(name string) (data []byte, ok bool, err error) {
+	f, err := r.fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	data, err = io.ReadAll(io.LimitReader(f, r.budget+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", name, err)
+	}
+	r.budget -= int64(len(data))
+	if r.budget < 0 {
+		return nil, false, errTooLarge
+	}
+	return data, true, nil
+}
+
+// Read reads a problem package from fsys.
+func Read(fsys fs.FS) (ml.Document, error) {
+	r := &reader{fsys: fsys, budget: MaxSize}
+
+	statement, ok, err := r.read(StatementFile)
+	if err != nil {
+		return ml.Document{}, err
+	}
+	if !ok {
+		return ml.Document{}, fmt.Errorf("package does not contain %s", StatementFile)
+	}
+	doc, err := ml.Parse(bytes.NewReader(statement))
+	if err != nil {
+		return doc, fmt.Errorf("%s: %w", StatementFile, err)
+	}
+
+	for name, field := range sources(&doc) {
+		if err := r.readSource(name, field); err != nil {
+			return doc, err
+		}
+	}
+
+	tests, err := r.readTests()
+	if err != nil {
+		return doc, err
+	}
+	if tests != nil {
+		if doc.Tests != nil {
+			return doc, fmt.Errorf("static tests are defined both in %s and %s", StatementFile, TestsDir)
+		}
+		doc.Tests = tests
+	}
+
+	return doc, nil
+}
+
+// readSource reads a source file into field, unless it does not exist.
+func (r *reader) readSource(name string, field *string) error {
+	data, ok, err := r.read(name)
+	if err != nil || !ok {
+		return err
+	}
+	if *field != "" {
+		return fmt.Errorf("source of %s is also defined in %s", name, StatementFile)
+	}
+	*field = string(data)
+	return nil
+}
+
+// readTests reads static tests from the tests directory, if it exists.
+func (r *reader) readTests() ([][]ml.Example, error) {
+	entries, err := fs.ReadDir(r.fsys, TestsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type testID struct{ group, test int }
+	inputs := make(map[testID]bool)
+	outputs := make(map[testID]bool)
+	groups := 0
+	for _, e := range entries {
+		id, ext, err := parseTestName(e.Name())
+		if err != nil || e.IsDir() {
+			return nil, fmt.Errorf("%s: unexpected file %q, want G.T.in or G.T.out", TestsDir, e.Name())
+		}
+		if ext == "in" {
+			inputs[testID(id)] = true
+		} else {
+			outputs[testID(id)] = true
+		}
+		groups = max(groups, id.group)
+	}
+
+	res := make([][]ml.Example, groups)
+	for g := range res {
+		for t := 1; inputs[testID{g + 1, t}] || outputs[testID{g + 1, t}]; t++ {
+			name := path.Join(TestsDir, fmt.Sprintf("%d.%d", g+1, t))
+			if !inputs[testID{g + 1, t}] || !outputs[testID{g + 1, t}] {
+				return nil, fmt.Errorf("%s: test must have both an input and an output", name)
+			}
+
+			input, _, err := r.read(name + ".in")
+			if err != nil {
+				return nil, err
+			}
+			output, _, err := r.read(name + ".out")
+			if err != nil {
+				return nil, err
+			}
+			res[g] = append(res[g], ml.Example{Input: string(input), Output: string(output)})
+		}
+		if len(res[g]) == 0 {
+			return nil, fmt.Errorf("%s: group %d does not have a test 1", TestsDir, g+1)
+		}
+	}
+
+	// every file must have been consumed
+	read := 0
+	for _, group := range res {
+		read += 2 * len(group)
+	}
+	if read != len(inputs)+len(outputs) {
+		return nil, fmt.Errorf("%s: tests must be numbered consecutively from 1", TestsDir)
+	}
+
+	return res, nil
+}
+
+// parseTestName parses a name of a test file, such as "1.2.in".
+func parseTestName(name string) (id struct{ group, test int }, ext string, err error) {
+	parts := strings.Split(name, ".")
+	if len(parts) != 3 || (parts[2] != "in" && parts[2] != "out") {
+		return id, "", errors.New("invalid test file name")
+	}
+	// only canonical numbers are accepted, so that two files can not name the same test
+	if id.group, err = strconv.Atoi(parts[0]); err != nil || id.group < 1 || strconv.Itoa(id.group) != parts[0] {
+		return id, "", errors.New("invalid group number")
+	}
+	if id.test, err = strconv.Atoi(parts[1]); err != nil || id.test < 1 || strconv.Itoa(id.test) != parts[1] {
+		return id, "", errors.New("invalid test number")
+	}
+	return id, parts[2], nil
+}
+
+// ReadDir reads a problem package from a directory.
+func ReadDir(dir string) (ml.Document, error) {
+	return Read(os.DirFS(dir))
+}
+
+// ReadZip reads a problem package from a zip archive of the given size.
+func ReadZip(r io.ReaderAt, size int64) (ml.Document, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return ml.Document{}, err
+	}
+
+	var fsys fs.FS = z
+	if _, err := fs.Stat(z, StatementFile); errors.Is(err, fs.ErrNotExist) {
+		// archive of the package directory
+		entries, err := fs.ReadDir(z, ".")
+		if err != nil {
+			return ml.Document{}, err
+		}
+		if len(entries) == 1 && entries[0].IsDir() {
+			if fsys, err = fs.Sub(z, entries[0].Name()); err != nil {
+				return ml.Document{}, err
+			}
+		}
+	}
+
+	return Read(fsys)
+}
+
+// Files returns contents of a package for doc, keyed by slash separated file names.
+// Sources and static tests are moved to their own files, everything else is kept in the statement.
+func Files(doc ml.Document) (map[string][]byte, error) {
+	res := make(map[string][]byte)
+
+	for name, field := range sources(&doc) {
+		if *field != "" {
+			res[name] = []byte(*field)
+			*field = ""
+		}
+	}
+
+	for g, group := range doc.Tests {
+		for t, test := range group {
+			name := path.Join(TestsDir, fmt.Sprintf("%d.%d", g+1, t+1))
+			res[name+".in"] = []byte(test.Input)
+			res[name+".out"] = []byte(test.Output)
+		}
+	}
+	doc.Tests = nil
+
+	var statement bytes.Buffer
+	if err := doc.WriteSyntax(&statement); err != nil {
+		return nil, err
+	}
+	res[StatementFile] = statement.Bytes()
+
+	return res, nil
+}
+
+// WriteDir writes a package for doc into dir, creating it if necessary.
+// Files of a previously written package, that is sources and the tests directory, are replaced,
+// so that stale files are not read back. Other files are kept.
+func WriteDir(dir string, doc ml.Document) error {
+	files, err := Files(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, TestsDir)); err != nil {
+		return err
+	}
+	for name := range sources(&ml.Document{}) {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, files[name], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteZip writes a package for doc as a zip archive.
+// Archive does not depend on the current time, so the same document always produces the same archive.
+func WriteZip(w io.Writer, doc ml.Document) error {
+	files, err := Files(doc)
+	if err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
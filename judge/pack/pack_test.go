@@ -0,0 +1,252 @@
+package pack_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/ml"
+	"github.com/TrueHopolok/braincode-/judge/ml/testhelper"
+	"github.com/TrueHopolok/braincode-/judge/pack"
+)
+
+const statement = `
+.task = Cat
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.dialect = eof=zero
+
+.paragraph = Print the input.
+`
+
+func file(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+
+func TestReadStaticTests(t *testing.T) {
+	fsys := fstest.MapFS{
+		"statement.ml":    file(statement),
+		"reference.bf":    file(",[.,]"),
+		"validator.lua":   file(`function validator(input) if #input == 0 then return "empty input" end end`),
+		"tests/1.1.in":    file("a"),
+		"tests/1.1.out":   file("a"),
+		"tests/1.2.in":    file("hello"),
+		"tests/1.2.out":   file("hello"),
+		"tests/2.1.in":    file("bye"),
+		"tests/2.1.out":   file("bye"),
+		"tests/README.md": nil,
+	}
+	if _, err := pack.Read(fsys); err == nil {
+		t.Fatal("expected an error for an unexpected file in tests")
+	}
+	delete(fsys, "tests/README.md")
+
+	doc, err := pack.Read(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ReferenceBF != ",[.,]" || !strings.Contains(doc.Validator, "validator") {
+		t.Errorf("sources were not read: %q, %q", doc.ReferenceBF, doc.Validator)
+	}
+	wantTests := [][]ml.Example{
+		{{Input: "a", Output: "a"}, {Input: "hello", Output: "hello"}},
+		{{Input: "bye", Output: "bye"}},
+	}
+	testhelper.DiffValues(t, wantTests, doc.Tests)
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := judge.NewJudge(1)
+	defer j.Close()
+	for _, group := range j.Judge(p, ",[.,]") {
+		for _, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("got %v, want Accept", v.Error())
+			}
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"no statement": {
+			"checker.lua": file("function solution(input) return input end"),
+		},
+		"inlined and file": {
+			"statement.ml": file(statement + ".reference\n,[.,]\n..\n"),
+			"reference.bf": file(",[.,]"),
+		},
+		"tests in statement and files": {
+			"statement.ml":  file(statement + ".test\n.input = a\n.output = a\n..\n"),
+			"tests/1.1.in":  file("a"),
+			"tests/1.1.out": file("a"),
+		},
+		"validator inlined and file": {
+			"statement.ml": file(statement + ".bfvalidator\n+.\n..\n"),
+			"validator.bf": file("+."),
+		},
+		"missing output": {
+			"statement.ml": file(statement),
+			"tests/1.1.in": file("a"),
+		},
+		"gap in tests": {
+			"statement.ml":  file(statement),
+			"tests/1.1.in":  file("a"),
+			"tests/1.1.out": file("a"),
+			"tests/1.3.in":  file("a"),
+			"tests/1.3.out": file("a"),
+		},
+		"non canonical test name": {
+			"statement.ml":   file(statement),
+			"tests/1.1.in":   file("a"),
+			"tests/1.1.out":  file("a"),
+			"tests/01.1.in":  file("b"),
+			"tests/01.1.out": file("b"),
+		},
+		"empty group": {
+			"statement.ml":  file(statement),
+			"tests/2.1.in":  file("a"),
+			"tests/2.1.out": file("a"),
+		},
+	}
+
+	for name, fsys := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := pack.Read(fsys); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	const data = statement + `
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {{"a", "hello"}}
+..
+
+.validator
+function validator(input)
+	if #input == 0 then
+		return "empty input"
+	end
+end
+..
+`
+
+	was, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := pack.WriteZip(&buf, was); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	testhelper.DiffValues(t, []string{"checker.lua", "statement.ml", "validator.lua"}, names)
+
+	got, err := pack.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelper.DiffValues(t, was, got)
+
+	var again bytes.Buffer
+	if err := pack.WriteZip(&again, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("archive is not deterministic")
+	}
+}
+
+func TestReadZipDirectory(t *testing.T) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for name, data := range map[string]string{
+		"cat/statement.ml": statement,
+		"cat/checker.lua":  "function solution(input) return input end test_data = {{\"a\"}}",
+	} {
+		f, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := pack.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc.Lua, "test_data") {
+		t.Errorf("lua script was not read: %q", doc.Lua)
+	}
+}
+
+func TestWriteDirReplacesPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	was, err := ml.Parse(strings.NewReader(statement + `
+.reference
+,[.,]
+..
+.test
+.input = a
+.output = a
+..
+.test
+.input = b
+.output = b
+..
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pack.WriteDir(dir, was); err != nil {
+		t.Fatal(err)
+	}
+
+	// fewer tests and no reference solution
+	want, err := ml.Parse(strings.NewReader(statement + ".test\n.input = c\n.output = c\n..\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pack.WriteDir(dir, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := pack.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lines are lost, because tests are stored in their own files
+	for _, group := range want.Tests {
+		for i := range group {
+			group[i].Line = 0
+		}
+	}
+	testhelper.DiffValues(t, want, got)
+}
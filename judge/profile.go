@@ -0,0 +1,93 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+)
+
+// Profile is an execution profile of a submission on a single test. See [ProfileSlowest].
+type Profile struct {
+	Group int    // Group index of the profiled test.
+	Test  int    // Test index of the profiled test inside its group.
+	Input string // Input of the profiled test.
+
+	bf.Profile
+}
+
+// ProfileSlowest runs submition on all tests of a problem and profiles the one that took the most steps.
+// Output is not checked, runtime errors and exceeded limits are not considered failures.
+//
+// Unlike [Judge.Judge], tests are executed sequentially in the calling goroutine.
+func ProfileSlowest(ctx context.Context, p Problem, submition string) (Profile, error) {
+	if p.Interactor != nil {
+		return Profile{}, errors.New("interactive problems can not be profiled")
+	}
+	if p.Memory <= 0 {
+		p.Memory = math.MaxInt
+	}
+	if p.Steps <= 0 {
+		p.Steps = math.MaxInt
+	}
+
+	bc, err := bf.CompileDialect(submition, p.Instructions, p.Dialect)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	tests, err := p.GenerateInput()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	res := Profile{Group: -1}
+	slowest := -1
+	for groupI, group := range tests {
+		for testI, inp := range group {
+			s, err := profileRun(ctx, p, bc, inp, false)
+			if err != nil {
+				return Profile{}, err
+			}
+
+			if steps := p.Steps - s.RemainingSteps(); steps > slowest {
+				slowest = steps
+				res.Group, res.Test, res.Input = groupI, testI, inp
+			}
+		}
+	}
+
+	if res.Group < 0 {
+		return Profile{}, errors.New("problem has no tests")
+	}
+
+	s, err := profileRun(ctx, p, bc, res.Input, true)
+	if err != nil {
+		return Profile{}, err
+	}
+	res.Profile = s.Profile()
+
+	return res, nil
+}
+
+// profileRun runs bc on input. Only cancellation of ctx is reported as an error.
+func profileRun(ctx context.Context, p Problem, bc bf.ByteCode, input string, profile bool) (*bf.State, error) {
+	runCtx := ctx
+	if p.Time > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, p.Time)
+		defer cancel()
+	}
+
+	// output is not used, but it is limited like in judging, so that the run stops at the same point
+	s := bf.NewState(bc, strings.NewReader(input), new(limitedBuffer), p.Steps, p.Memory)
+	if profile {
+		s.EnableProfiling()
+	}
+
+	_ = s.RunContext(runCtx)
+
+	return &s, ctx.Err()
+}
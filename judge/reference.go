@@ -0,0 +1,154 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+// ReferenceReport is a result of judging a reference solution. See [Judge.CheckReference].
+type ReferenceReport struct {
+	Verdicts [][]Verdict
+
+	// Peak resource usage over all tests.
+	Steps  int
+	Memory int
+	Time   time.Duration
+
+	// Limits of the problem.
+	StepLimit   int
+	MemoryLimit int
+	TimeLimit   time.Duration
+}
+
+// CheckReference judges a reference solution of a problem, so that broken generators and checkers
+// can be found before the problem is published.
+//
+// Unlike [Judge.JudgeEarlyExit], all tests are judged even if some of them fail.
+func (j Judge) CheckReference(ctx context.Context, p Problem, reference string) ReferenceReport {
+	res := ReferenceReport{
+		Verdicts:    j.JudgeContext(ctx, p, reference),
+		StepLimit:   p.Steps,
+		MemoryLimit: p.Memory,
+		TimeLimit:   p.Time,
+	}
+
+	for _, group := range res.Verdicts {
+		for _, v := range group {
+			res.Steps = max(res.Steps, v.Steps)
+			res.Memory = max(res.Memory, v.Memory)
+			res.Time = max(res.Time, v.Time)
+		}
+	}
+
+	return res
+}
+
+// Err returns an error listing all tests that were not accepted, or nil if all of them were.
+func (r ReferenceReport) Err() error {
+	var failed []string
+	for i, group := range r.Verdicts {
+		for j, v := range group {
+			if v.Status == StatusAccept {
+				continue
+			}
+			if v.Status == StatusCompilationFailed || v.Status == StatusSourceSizeLimit {
+				// not tied to any test
+				failed = append(failed, fmt.Sprintf("%v: %s", v.Status, v.Comment))
+				continue
+			}
+			failed = append(failed, fmt.Sprintf("test %d.%d: %v", i+1, j+1, v.Error()))
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("reference solution failed %d tests (%s):\n%s", len(failed), r.Usage(), strings.Join(failed, "\n"))
+}
+
+// Usage formats peak resource usage relative to limits of the problem.
+func (r ReferenceReport) Usage() string {
+	usage := fmt.Sprintf("steps %d of %d (%s), memory %d of %d (%s)",
+		r.Steps, r.StepLimit, percent(float64(r.Steps), float64(r.StepLimit)),
+		r.Memory, r.MemoryLimit, percent(float64(r.Memory), float64(r.MemoryLimit)))
+	if r.TimeLimit > 0 {
+		usage += fmt.Sprintf(", time %v of %v (%s)", r.Time, r.TimeLimit, percent(float64(r.Time), float64(r.TimeLimit)))
+	}
+	return usage
+}
+
+func percent(used, limit float64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.1f%%", used/limit*100)
+}
+
+// CheckExamples checks outputs of all examples of a document with the output checker of a problem,
+// so that examples that contradict the checker are found before the problem is published.
+// Returned error lists every example that is not accepted together with its locale, number and line.
+//
+// Examples of interactive problems are not checked, as well as examples that are unknown to a table driven checker.
+func CheckExamples(doc ml.Document, p Problem) error {
+	if p.Interactor != nil || p.OutputChecker == nil {
+		return nil
+	}
+
+	// examples shared by several locales are checked once
+	type exampleKey struct {
+		index   int
+		example ml.Example
+	}
+	seen := make(map[exampleKey]bool)
+
+	var failed []string
+	// default locale is checked last, because it may share examples with another locale
+	locales := slices.Sorted(maps.Keys(doc.Localizations))
+	if len(locales) > 0 && locales[0] == "" {
+		locales = append(locales[1:], "")
+	}
+	for _, locale := range locales {
+		l := doc.Localizations[locale]
+		if l == nil {
+			continue
+		}
+
+		for i, example := range l.Examples() {
+			key := exampleKey{i, example}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if answers, ok := p.OutputChecker.(listSolution); ok {
+				if _, found := answers[example.Input]; !found {
+					continue
+				}
+			}
+
+			v := p.CheckOutput(example.Input, example.Output)
+			if v.Status == StatusAccept {
+				continue
+			}
+			where := fmt.Sprintf("example %d", i+1)
+			if example.Line > 0 {
+				where += fmt.Sprintf(" at line %d", example.Line)
+			}
+			if locale != "" {
+				where += fmt.Sprintf(" (locale %s)", locale)
+			}
+			failed = append(failed, fmt.Sprintf("%s: %v", where, v.Error()))
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("checker rejected %d examples:\n%s", len(failed), strings.Join(failed, "\n"))
+}
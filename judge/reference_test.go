@@ -0,0 +1,145 @@
+package judge_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+func TestCheckReference(t *testing.T) {
+	const data = `
+.task = Cat
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {
+	{"hello", "a"},
+	{""},
+}
+..
+
+.reference
+,[.,]
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ReferenceBF == "" {
+		t.Fatal("reference block was not parsed")
+	}
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	// every test reads past the end of input
+	report := j.CheckReference(context.Background(), p, judge.ReferenceSolution(doc))
+	err = report.Err()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"failed 3 tests", "test 1.2: InputExhausted", "test 2.1: InputExhausted", "of 10000"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if report.Steps == 0 || report.StepLimit != 10000 || report.MemoryLimit != 200 {
+		t.Errorf("usage = %s", report.Usage())
+	}
+
+	p.Dialect.EOF = bf.EOFZero
+	report = j.CheckReference(context.Background(), p, judge.ReferenceSolution(doc))
+	if err := report.Err(); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCheckExamples(t *testing.T) {
+	const data = `
+.task = Upper
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+.compare = trailing-space
+
+.lua
+function solution(input)
+	return string.upper(input)
+end
+
+test_data = {{"a"}}
+..
+
+.en
+.example
+.input = hello
+.output
+HELLO
+..
+..
+.quote
+.example
+.input = abc
+.output = abd
+..
+..
+..
+
+.ru
+.example
+.input = ok
+.output = OK
+..
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = judge.CheckExamples(doc, p)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"rejected 1 examples", "example 2 at line 25 (locale en): WrongAnswer"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	// examples unknown to static tests are not checked
+	doc.Lua = ""
+	doc.Tests = [][]ml.Example{{{Input: "abc", Output: "ABC"}}}
+	p, err = judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = judge.CheckExamples(doc, p)
+	if err == nil || !strings.Contains(err.Error(), "example 2 at line 25 (locale en)") || strings.Contains(err.Error(), "example 1") {
+		t.Errorf("err = %v", err)
+	}
+}
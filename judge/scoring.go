@@ -0,0 +1,183 @@
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoringMode decides how credit of tests in a group is combined. See [Scoring].
+type ScoringMode int
+
+const (
+	ScoringMin ScoringMode = iota // Group earns the minimum credit of its tests.
+	ScoringSum                    // Group earns the average credit of its tests.
+)
+
+var scoringModeNames = []string{ScoringMin: "min", ScoringSum: "sum"}
+
+// Scoring is a scoring scheme of a problem.
+//
+// Zero value is the scheme used by [CalculateScore]: a group is worth the number of its tests
+// and only counts if all of them pass.
+type Scoring struct {
+	Mode   ScoringMode
+	Points []float64 // Points of every group. If nil, a group is worth the number of its tests.
+}
+
+// ScoreBreakdown is a score of a submission split by test groups. See [Scoring.Score].
+type ScoreBreakdown struct {
+	Points    []float64 // Points earned in every group.
+	MaxPoints []float64 // Maximum points of every group.
+}
+
+// Total returns the sum of earned points.
+func (b ScoreBreakdown) Total() float64 {
+	var res float64
+	for _, p := range b.Points {
+		res += p
+	}
+	return res
+}
+
+// Max returns the sum of maximum points.
+func (b ScoreBreakdown) Max() float64 {
+	var res float64
+	for _, p := range b.MaxPoints {
+		res += p
+	}
+	return res
+}
+
+// Fraction returns earned fraction of maximum points.
+// Returned value is NaN if there are no points to earn and in range [0, 1] in all other cases.
+func (b ScoreBreakdown) Fraction() float64 {
+	return b.Total() / b.Max()
+}
+
+// Credit returns partial credit of a verdict in range [0, 1].
+// Accepted tests earn full credit, [StatusPartial] earns [Verdict.Score], all other statuses earn nothing.
+func (v Verdict) Credit() float64 {
+	switch v.Status {
+	case StatusAccept:
+		return 1
+	case StatusPartial:
+		return min(max(v.Score, 0), 1)
+	default:
+		return 0
+	}
+}
+
+// Score calculates score of a given verdict set.
+//
+// If [Scoring.Points] is set, breakdown contains all declared groups, even if v has less of them.
+// Groups without declared points are worth nothing.
+func (s Scoring) Score(v [][]Verdict) ScoreBreakdown {
+	n := len(v)
+	if s.Points != nil {
+		n = max(n, len(s.Points))
+	}
+
+	res := ScoreBreakdown{
+		Points:    make([]float64, n),
+		MaxPoints: make([]float64, n),
+	}
+
+	for i := range n {
+		var group []Verdict
+		if i < len(v) {
+			group = v[i]
+		}
+
+		if s.Points == nil {
+			res.MaxPoints[i] = float64(len(group))
+		} else if i < len(s.Points) {
+			res.MaxPoints[i] = s.Points[i]
+		}
+
+		if len(group) == 0 {
+			continue
+		}
+
+		var credit float64
+		switch s.Mode {
+		case ScoringSum:
+			for _, test := range group {
+				credit += test.Credit()
+			}
+			credit /= float64(len(group))
+		default:
+			credit = 1
+			for _, test := range group {
+				credit = min(credit, test.Credit())
+			}
+		}
+
+		res.Points[i] = credit * res.MaxPoints[i]
+	}
+
+	return res
+}
+
+// String formats scoring in a format accepted by [ParseScoring].
+func (s Scoring) String() string {
+	fields := []string{strconv.Itoa(int(s.Mode))}
+	if s.Mode >= 0 && int(s.Mode) < len(scoringModeNames) {
+		fields[0] = scoringModeNames[s.Mode]
+	}
+	for _, p := range s.Points {
+		fields = append(fields, strconv.FormatFloat(p, 'g', -1, 64))
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseScoring parses a scoring mode ("min" or "sum") optionally followed by points of every group,
+// separated by whitespace. Empty string is the default scoring.
+func ParseScoring(s string) (Scoring, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Scoring{}, nil
+	}
+
+	var res Scoring
+	switch fields[0] {
+	case "min":
+		res.Mode = ScoringMin
+	case "sum":
+		res.Mode = ScoringSum
+	default:
+		return Scoring{}, fmt.Errorf("scoring mode %q must be one of %s", fields[0], strings.Join(scoringModeNames, ", "))
+	}
+
+	for _, f := range fields[1:] {
+		p, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return Scoring{}, fmt.Errorf("group points %q: %w", f, err)
+		}
+		if p < 0 || math.IsInf(p, 0) || math.IsNaN(p) {
+			return Scoring{}, fmt.Errorf("group points %q must be a non negative number", f)
+		}
+		res.Points = append(res.Points, p)
+	}
+
+	return res, res.Validate()
+}
+
+// Validate reports whether scoring is supported.
+func (s Scoring) Validate() error {
+	if s.Mode < 0 || int(s.Mode) >= len(scoringModeNames) {
+		return fmt.Errorf("unsupported scoring mode %d", s.Mode)
+	}
+	if s.Points != nil {
+		var total float64
+		for _, p := range s.Points {
+			total += p
+		}
+		if total <= 0 {
+			return errors.New("groups must be worth at least some points")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package judge_test
+
+import (
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+)
+
+func TestScoring(t *testing.T) {
+	v := [][]judge.Verdict{
+		{{Status: judge.StatusAccept}, {Status: judge.StatusWrongAnswer}},
+		{{Status: judge.StatusPartial, Score: 0.5}, {Status: judge.StatusAccept}},
+	}
+
+	tests := []struct {
+		scoring string
+		total   float64
+		max     float64
+	}{
+		{"", 1, 4},
+		{"min", 1, 4},
+		{"sum", 2.5, 4},
+		{"min 10 20", 10, 30},
+		{"sum 10 20", 20, 30},
+		{"sum 10 20 70", 20, 100},
+	}
+
+	for _, tt := range tests {
+		s, err := judge.ParseScoring(tt.scoring)
+		if err != nil {
+			t.Fatalf("%q: err = %v", tt.scoring, err)
+		}
+
+		b := s.Score(v)
+		if b.Total() != tt.total || b.Max() != tt.max {
+			t.Errorf("%q: got %v/%v points want %v/%v", tt.scoring, b.Total(), b.Max(), tt.total, tt.max)
+		}
+
+		again, err := judge.ParseScoring(s.String())
+		if err != nil || again.String() != s.String() {
+			t.Errorf("%q: round trip got %q (err = %v) want %q", tt.scoring, again, err, s)
+		}
+	}
+
+	if got, want := judge.CalculateScore(v), 0.25; got != want {
+		t.Errorf("CalculateScore = %v want %v", got, want)
+	}
+}
+
+func TestScoringSerialization(t *testing.T) {
+	s, err := judge.ParseScoring("sum 10 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a"}, {"b"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", "a"}, judge.Pair{"b", "b"}),
+		Scoring:        s,
+	}
+
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got judge.Problem
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got.Scoring.String() != s.String() {
+		t.Errorf("got scoring %q want %q", got.Scoring, s)
+	}
+}
+
+func TestParseScoringErrors(t *testing.T) {
+	for _, s := range []string{"max", "min -1", "sum 0 0", "min ten", "10 20"} {
+		if _, err := judge.ParseScoring(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestPartialCredit(t *testing.T) {
+	const checker = `
+	function checker(input, output)
+		partial(#output / #input)
+	end
+
+	test_data = {"abcd"}
+	`
+
+	c, err := judge.NewLuaChecker(checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		output string
+		status judge.Status
+	}{
+		{"", judge.StatusWrongAnswer},
+		{"ab", judge.StatusPartial},
+		{"abcd", judge.StatusAccept},
+		{"abcdef", judge.StatusAccept},
+	}
+
+	for _, tt := range tests {
+		v := c.CheckOutput("abcd", tt.output)
+		if v.Status != tt.status {
+			t.Errorf("%q: got %v want %v", tt.output, v, tt.status)
+		}
+	}
+
+	if v := c.CheckOutput("abcd", "ab"); v.Credit() != 0.5 {
+		t.Errorf("got credit %v want 0.5", v.Credit())
+	}
+}
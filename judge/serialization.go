@@ -3,173 +3,415 @@ package judge
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/gob"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"math"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/TrueHopolok/braincode-/judge/bf"
 	"github.com/TrueHopolok/braincode-/judge/lua"
 )
 
-type serializedGenerator struct {
-	List *listGenerator
-	BF   *bfGenerator
-	Lua  *luaGenerator
+const (
+	wireFormatV1 = iota + 1 // gob encoded generator and checker, see serialization_legacy.go
+	wireFormatV2            // replaces gob with tagged sections, adds settings, interactors and validators
+
+	wireFormat = wireFormatV2 // format written by [Problem.MarshalBinary]
+)
+
+// Kinds of sections, see [Problem.AppendBinary].
+// Kinds are a part of the format and must never change, types implementing them may be renamed freely.
+const (
+	sectionListGenerator      = "generator/list"       // uvarint group count, then every group as uvarint test count and strings
+	sectionBFGenerator        = "generator/bf"         // byte code
+	sectionLuaGenerator       = "generator/lua"        // lua source
+	sectionSeededLuaGenerator = "generator/lua-seeded" // string lua source, string group declarations, see [lua.ParseGroupSpecs]
+	sectionListSolution       = "checker/list"         // uvarint pair count, then input and output strings of every pair sorted by input
+	sectionBFSolution         = "checker/bf-solution"  // string byte code, uvarint steps, uvarint memory, string dialect
+	sectionBFChecker          = "checker/bf"           // byte code
+	sectionLuaChecker         = "checker/lua"          // lua source
+	sectionLuaInteractor      = "interactor/lua"       // lua source
+	sectionBFValidator        = "validator/bf"         // byte code
+	sectionLuaValidator       = "validator/lua"        // lua source
+)
+
+// section is a serialized component of a problem.
+type section struct {
+	kind    string
+	payload []byte
+}
+
+// role returns the part of the kind before the slash, such as "generator".
+func (s section) role() string {
+	role, _, _ := strings.Cut(s.kind, "/")
+	return role
 }
 
-func marshalGenerator(enc *gob.Encoder, gen InputGenerator) error {
-	var val serializedGenerator
+func appendSection(buf []byte, s section) []byte {
+	buf = appendString(buf, s.kind)
+	buf = binary.AppendUvarint(buf, uint64(len(s.payload)))
+	return append(buf, s.payload...)
+}
+
+func readSection(r *bytes.Reader) (section, error) {
+	kind, err := readString(r)
+	if err != nil {
+		return section{}, fmt.Errorf("section kind: %w", err)
+	}
+	payload, err := readString(r)
+	if err != nil {
+		return section{}, fmt.Errorf("section %s: %w", kind, err)
+	}
+	return section{kind, []byte(payload)}, nil
+}
+
+func generatorSection(gen InputGenerator) (section, error) {
 	switch g := gen.(type) {
-	case bfGenerator:
-		val.BF = &g
 	case listGenerator:
-		val.List = &g
+		buf := binary.AppendUvarint(nil, uint64(len(g)))
+		for _, group := range g {
+			buf = binary.AppendUvarint(buf, uint64(len(group)))
+			for _, test := range group {
+				buf = appendString(buf, test)
+			}
+		}
+		return section{sectionListGenerator, buf}, nil
+	case bfGenerator:
+		buf, err := bf.ByteCode(g).MarshalBinary()
+		return section{sectionBFGenerator, buf}, err
 	case luaGenerator:
-		val.Lua = &g
+		return section{sectionLuaGenerator, []byte(g)}, nil
+	case seededLuaGenerator:
+		specs := make([]string, len(g.Groups))
+		for i, spec := range g.Groups {
+			specs[i] = spec.String()
+		}
+		buf := appendString(nil, g.Source)
+		buf = appendString(buf, strings.Join(specs, "\n"))
+		return section{sectionSeededLuaGenerator, buf}, nil
 	case *smartGenerator:
-		return marshalGenerator(enc, g.InputGenerator)
+		return generatorSection(g.InputGenerator)
 	default:
-		return fmt.Errorf("unexpected generator: %T", g)
+		return section{}, fmt.Errorf("unexpected generator: %T", g)
 	}
-
-	return enc.Encode(&val)
 }
 
-func unmarshalGenerator(dec *gob.Decoder) (InputGenerator, error) {
-	var val serializedGenerator
-	if err := dec.Decode(&val); err != nil {
-		return nil, err
+func (s section) generator() (InputGenerator, error) {
+	r := bytes.NewReader(s.payload)
+	var res InputGenerator
+	switch s.kind {
+	case sectionListGenerator:
+		groups, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if groups > uint64(r.Len()) {
+			return nil, errors.New("group count is out of bounds")
+		}
+		tests := make(listGenerator, groups)
+		for i := range tests {
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			if n > uint64(r.Len()) {
+				return nil, errors.New("test count is out of bounds")
+			}
+			tests[i] = make([]string, n)
+			for j := range tests[i] {
+				if tests[i][j], err = readString(r); err != nil {
+					return nil, err
+				}
+			}
+		}
+		res = tests
+	case sectionBFGenerator:
+		var bc bf.ByteCode
+		if err := bc.UnmarshalBinary(s.payload); err != nil {
+			return nil, err
+		}
+		return &smartGenerator{bfGenerator(bc)}, nil
+	case sectionLuaGenerator:
+		return &smartGenerator{luaGenerator(s.payload)}, nil
+	case sectionSeededLuaGenerator:
+		source, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		text, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := lua.ParseGroupSpecs(text)
+		if err != nil {
+			return nil, err
+		}
+		res = seededLuaGenerator{source, groups}
+	default:
+		return nil, fmt.Errorf("unknown generator kind %q", s.kind)
 	}
+	return res, checkTrailing(r)
+}
 
-	if val.Lua != nil {
-		return &smartGenerator{*val.Lua}, nil
-	}
-	if val.List != nil {
-		return *val.List, nil
-	}
-	if val.BF != nil {
-		return &smartGenerator{val.BF}, nil
+func checkerSection(checker OutputChecker) (section, error) {
+	switch c := checker.(type) {
+	case listSolution:
+		buf := binary.AppendUvarint(nil, uint64(len(c)))
+		for _, input := range slices.Sorted(maps.Keys(c)) {
+			buf = appendString(buf, input)
+			buf = appendString(buf, c[input])
+		}
+		return section{sectionListSolution, buf}, nil
+	case bfSolution:
+		code, err := c.bc.MarshalBinary()
+		if err != nil {
+			return section{}, err
+		}
+		buf := appendString(nil, string(code))
+		buf = binary.AppendUvarint(buf, uint64(max(c.steps, 0)))
+		buf = binary.AppendUvarint(buf, uint64(max(c.memory, 0)))
+		buf = appendString(buf, c.bc.Dialect().String())
+		return section{sectionBFSolution, buf}, nil
+	case bfChecker:
+		buf, err := bf.ByteCode(c).MarshalBinary()
+		return section{sectionBFChecker, buf}, err
+	case *luaChecker:
+		return section{sectionLuaChecker, []byte(c.Source())}, nil
+	default:
+		return section{}, fmt.Errorf("unexpected checker: %T", c)
 	}
-	return nil, errors.New("value did not contain any known generator")
 }
 
-func MarshalGenerator(g InputGenerator) ([]byte, error) {
-	return AppendGenerator(g, nil)
+func (s section) checker() (OutputChecker, error) {
+	r := bytes.NewReader(s.payload)
+	var res OutputChecker
+	switch s.kind {
+	case sectionListSolution:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(r.Len()) {
+			return nil, errors.New("pair count is out of bounds")
+		}
+		answers := make(listSolution, n)
+		for range n {
+			input, err := readString(r)
+			if err != nil {
+				return nil, err
+			}
+			if answers[input], err = readString(r); err != nil {
+				return nil, err
+			}
+		}
+		res = answers
+	case sectionBFSolution:
+		code, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		steps, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		memory, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if steps > math.MaxInt || memory > math.MaxInt {
+			return nil, errors.New("limit integer overflow (is this system 32 bit?)")
+		}
+		text, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		dialect, err := bf.ParseDialect(text)
+		if err != nil {
+			return nil, err
+		}
+		var bc bf.ByteCode
+		if err := bc.UnmarshalBinary([]byte(code)); err != nil {
+			return nil, err
+		}
+		if bc, err = bc.WithDialect(dialect); err != nil {
+			return nil, err
+		}
+		res = bfSolution{bc: bc, steps: int(steps), memory: int(memory)}
+	case sectionBFChecker:
+		var bc bf.ByteCode
+		if err := bc.UnmarshalBinary(s.payload); err != nil {
+			return nil, err
+		}
+		return bfChecker(bc), nil
+	case sectionLuaChecker:
+		return NewLuaChecker(string(s.payload))
+	default:
+		return nil, fmt.Errorf("unknown checker kind %q", s.kind)
+	}
+	return res, checkTrailing(r)
 }
 
-func AppendGenerator(g InputGenerator, b []byte) ([]byte, error) {
-	buf := bytes.NewBuffer(b)
-	err := marshalGenerator(gob.NewEncoder(buf), g)
-	return buf.Bytes(), err
+func interactorSection(interactor Interactor) (section, error) {
+	switch it := interactor.(type) {
+	case *luaInteractor:
+		return section{sectionLuaInteractor, []byte(it.Source())}, nil
+	default:
+		return section{}, fmt.Errorf("unexpected interactor: %T", it)
+	}
 }
 
-func UnmarshalGenerator(b []byte) (InputGenerator, error) {
-	buf := bytes.NewReader(b)
-	res, err := unmarshalGenerator(gob.NewDecoder(buf))
-	if err != nil {
-		return nil, err
-	}
-	if buf.Len() > 0 {
-		return nil, fmt.Errorf("buffer contains %d trailing junk bytes", buf.Len())
+func (s section) interactor() (Interactor, error) {
+	switch s.kind {
+	case sectionLuaInteractor:
+		return NewLuaInteractor(string(s.payload))
+	default:
+		return nil, fmt.Errorf("unknown interactor kind %q", s.kind)
 	}
-	return res, nil
 }
 
-type serializedChecker struct {
-	List       *listSolution
-	BFSolution *bfSolution
-	BFChecker  *bfChecker
-	Lua        *lua.Checker
+func validatorSection(validator InputValidator) (section, error) {
+	switch v := validator.(type) {
+	case bfValidator:
+		buf, err := bf.ByteCode(v).MarshalBinary()
+		return section{sectionBFValidator, buf}, err
+	case *luaValidator:
+		return section{sectionLuaValidator, []byte(v.Source())}, nil
+	default:
+		return section{}, fmt.Errorf("unexpected validator: %T", v)
+	}
 }
 
-func marshalChecker(enc *gob.Encoder, checker OutputChecker) error {
-	var val serializedChecker
-	switch che := checker.(type) {
-	case bfChecker:
-		val.BFChecker = &che
-	case bfSolution:
-		val.BFSolution = &che
-	case listSolution:
-		val.List = &che
-	case *luaChecker:
-		val.Lua = &che.Checker
+func (s section) validator() (InputValidator, error) {
+	switch s.kind {
+	case sectionBFValidator:
+		var bc bf.ByteCode
+		if err := bc.UnmarshalBinary(s.payload); err != nil {
+			return nil, err
+		}
+		return bfValidator(bc), nil
+	case sectionLuaValidator:
+		return NewLuaValidator(string(s.payload))
 	default:
-		return fmt.Errorf("unexpected checker: %T", che)
+		return nil, fmt.Errorf("unknown validator kind %q", s.kind)
 	}
+}
 
-	return enc.Encode(&val)
+func MarshalGenerator(g InputGenerator) ([]byte, error) {
+	return AppendGenerator(g, nil)
 }
 
-func unmarshalChecker(dec *gob.Decoder) (OutputChecker, error) {
-	var val serializedChecker
-	if err := dec.Decode(&val); err != nil {
-		return nil, err
+// AppendGenerator appends a generator section, see [Problem.AppendBinary].
+func AppendGenerator(g InputGenerator, b []byte) ([]byte, error) {
+	s, err := generatorSection(g)
+	if err != nil {
+		return b, err
 	}
+	return appendSection(b, s), nil
+}
 
-	if val.Lua != nil {
-		return &luaChecker{*val.Lua}, nil
-	}
-	if val.BFChecker != nil {
-		return *val.BFChecker, nil
-	}
-	if val.BFSolution != nil {
-		return *val.BFSolution, nil
+func UnmarshalGenerator(b []byte) (InputGenerator, error) {
+	r := bytes.NewReader(b)
+	s, err := readSection(r)
+	if err != nil {
+		return nil, err
 	}
-	if val.List != nil {
-		return *val.List, nil
+	if err := checkTrailing(r); err != nil {
+		return nil, err
 	}
-	return nil, errors.New("value did not contain any known checker")
+	return s.generator()
 }
 
 func MarshalChecker(c OutputChecker) ([]byte, error) {
 	return AppendChecker(c, nil)
 }
 
+// AppendChecker appends a checker section, see [Problem.AppendBinary].
 func AppendChecker(c OutputChecker, b []byte) ([]byte, error) {
-	buf := bytes.NewBuffer(b)
-	err := marshalChecker(gob.NewEncoder(buf), c)
-	return buf.Bytes(), err
+	s, err := checkerSection(c)
+	if err != nil {
+		return b, err
+	}
+	return appendSection(b, s), nil
 }
 
 func UnmarshalChecker(b []byte) (OutputChecker, error) {
-	buf := bytes.NewReader(b)
-	res, err := unmarshalChecker(gob.NewDecoder(buf))
+	r := bytes.NewReader(b)
+	s, err := readSection(r)
 	if err != nil {
 		return nil, err
 	}
-	if buf.Len() > 0 {
-		return nil, fmt.Errorf("buffer contains %d trailing junk bytes", buf.Len())
+	if err := checkTrailing(r); err != nil {
+		return nil, err
 	}
-	return res, nil
+	return s.checker()
 }
 
-const (
-	wireFormatV1 = iota + 1
-)
-
 func (p *Problem) MarshalBinary() ([]byte, error) {
 	return p.AppendBinary(nil)
 }
 
+// AppendBinary appends a problem encoded in the current format:
+//
+//	uvarint format version
+//	uvarint instructions, memory, steps and time limit in nanoseconds
+//	string  dialect, scoring and comparison as formatted by their String methods
+//	uvarint section count
+//	section generator, then checker or interactor, then optional validator
+//
+// Every string is prefixed with its length as uvarint. Section is a pair of strings:
+// a kind, such as "checker/lua", and a payload, that is documented next to the kind.
+// Byte code is encoded by [bf.ByteCode.MarshalBinary].
+//
+// Encoding does not depend on names of Go types, so they can be refactored without breaking stored problems.
+// Any change of the encoding must add a new format version, [Problem.UnmarshalBinary] reads all previous versions.
 func (p *Problem) AppendBinary(buf []byte) ([]byte, error) {
-	buf = binary.AppendUvarint(buf, wireFormatV1)
+	buf = binary.AppendUvarint(buf, wireFormat)
 	buf = binary.AppendUvarint(buf, uint64(max(p.Instructions, 0)))
 	buf = binary.AppendUvarint(buf, uint64(max(p.Memory, 0)))
 	buf = binary.AppendUvarint(buf, uint64(max(p.Steps, 0)))
+	buf = binary.AppendUvarint(buf, uint64(max(p.Time, 0)))
+	buf = appendString(buf, p.Dialect.String())
+	buf = appendString(buf, p.Scoring.String())
+	buf = appendString(buf, p.Comparison.String())
 
-	b := bytes.NewBuffer(buf)
-	enc := gob.NewEncoder(b)
+	sections := make([]section, 0, 3)
 
-	if err := marshalGenerator(enc, p.InputGenerator); err != nil {
-		return b.Bytes(), err
+	s, err := generatorSection(p.InputGenerator)
+	if err != nil {
+		return buf, err
+	}
+	sections = append(sections, s)
+
+	if p.Interactor != nil {
+		s, err = interactorSection(p.Interactor)
+	} else {
+		s, err = checkerSection(p.OutputChecker)
+	}
+	if err != nil {
+		return buf, err
 	}
-	if err := marshalChecker(enc, p.OutputChecker); err != nil {
-		return b.Bytes(), err
+	sections = append(sections, s)
+
+	if p.Validator != nil {
+		s, err := validatorSection(p.Validator)
+		if err != nil {
+			return buf, err
+		}
+		sections = append(sections, s)
 	}
 
-	return b.Bytes(), nil
+	buf = binary.AppendUvarint(buf, uint64(len(sections)))
+	for _, s := range sections {
+		buf = appendSection(buf, s)
+	}
+	return buf, nil
 }
 
+// UnmarshalBinary decodes a problem encoded in the current or any previous format.
 func (p *Problem) UnmarshalBinary(buf []byte) error {
 	r := bytes.NewReader(buf)
 
@@ -177,55 +419,200 @@ func (p *Problem) UnmarshalBinary(buf []byte) error {
 	if err != nil {
 		return err
 	}
-	if ver != wireFormatV1 {
-		return fmt.Errorf("serialized version %v, but parser only recognizes v1", ver)
+	if ver < wireFormatV1 || ver > wireFormat {
+		return fmt.Errorf("serialized version %v, but parser only recognizes v1 to v%d", ver, wireFormat)
 	}
 
-	instr, err := binary.ReadUvarint(r)
+	res, err := readHeader(ver, r)
+	if err != nil {
+		return err
+	}
+
+	if ver == wireFormatV1 {
+		err = res.unmarshalLegacy(r)
+	} else {
+		err = res.unmarshalSections(r)
+	}
 	if err != nil {
 		return err
 	}
+
+	if err := checkTrailing(r); err != nil {
+		return err
+	}
+
+	*p = res
+	return nil
+}
+
+// readHeader decodes limits and settings of a problem stored in format ver.
+func readHeader(ver uint64, r *bytes.Reader) (Problem, error) {
+	instr, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Problem{}, err
+	}
 	if instr > math.MaxInt {
-		return errors.New("instruction count integer overflow (is this system 32 bit?)")
+		return Problem{}, errors.New("instruction count integer overflow (is this system 32 bit?)")
 	}
 
 	memory, err := binary.ReadUvarint(r)
 	if err != nil {
-		return err
+		return Problem{}, err
 	}
 	if memory > math.MaxInt {
-		return errors.New("memory limit integer overflow (is this system 32 bit?)")
+		return Problem{}, errors.New("memory limit integer overflow (is this system 32 bit?)")
 	}
 
 	steps, err := binary.ReadUvarint(r)
 	if err != nil {
-		return err
+		return Problem{}, err
 	}
 	if steps > math.MaxInt {
-		return errors.New("step limit integer overflow (is this system 32 bit?)")
+		return Problem{}, errors.New("step limit integer overflow (is this system 32 bit?)")
+	}
+
+	res := Problem{
+		Steps:        int(steps),
+		Memory:       int(memory),
+		Instructions: int(instr),
+	}
+	if ver == wireFormatV1 {
+		return res, nil
 	}
 
-	dec := gob.NewDecoder(r)
-	gen, err := unmarshalGenerator(dec)
+	timeLimit, err := binary.ReadUvarint(r)
 	if err != nil {
-		return err
+		return Problem{}, err
+	}
+	if timeLimit > math.MaxInt64 {
+		return Problem{}, errors.New("time limit integer overflow")
+	}
+	res.Time = time.Duration(timeLimit)
+
+	text, err := readString(r)
+	if err != nil {
+		return Problem{}, fmt.Errorf("dialect: %w", err)
+	}
+	if res.Dialect, err = bf.ParseDialect(text); err != nil {
+		return Problem{}, err
 	}
 
-	che, err := unmarshalChecker(dec)
+	text, err = readString(r)
+	if err != nil {
+		return Problem{}, fmt.Errorf("scoring: %w", err)
+	}
+	if res.Scoring, err = ParseScoring(text); err != nil {
+		return Problem{}, err
+	}
+
+	text, err = readString(r)
+	if err != nil {
+		return Problem{}, fmt.Errorf("comparison: %w", err)
+	}
+	if res.Comparison, err = ParseComparison(text); err != nil {
+		return Problem{}, err
+	}
+
+	return res, nil
+}
+
+// unmarshalSections decodes components of a problem stored in format v2 or later.
+func (p *Problem) unmarshalSections(r *bytes.Reader) error {
+	n, err := binary.ReadUvarint(r)
 	if err != nil {
 		return err
 	}
+	if n > uint64(r.Len()) {
+		return errors.New("section count is out of bounds")
+	}
 
-	if r.Len() > 0 {
-		return fmt.Errorf("buffer contains %d trailing junk bytes", r.Len())
+	for range n {
+		s, err := readSection(r)
+		if err != nil {
+			return err
+		}
+
+		switch s.role() {
+		case "generator":
+			if p.InputGenerator != nil {
+				return errors.New("more than one generator")
+			}
+			p.InputGenerator, err = s.generator()
+		case "checker", "interactor":
+			if p.OutputChecker != nil || p.Interactor != nil {
+				return errors.New("more than one checker or interactor")
+			}
+			if s.role() == "checker" {
+				p.OutputChecker, err = s.checker()
+			} else {
+				p.Interactor, err = s.interactor()
+			}
+		case "validator":
+			if p.Validator != nil {
+				return errors.New("more than one validator")
+			}
+			p.Validator, err = s.validator()
+		default:
+			return fmt.Errorf("unknown section kind %q", s.kind)
+		}
+		if err != nil {
+			return fmt.Errorf("section %s: %w", s.kind, err)
+		}
+	}
+
+	if p.InputGenerator == nil {
+		return errors.New("problem does not have a generator")
+	}
+	if p.OutputChecker == nil && p.Interactor == nil {
+		return errors.New("problem does not have a checker or an interactor")
+	}
+	return nil
+}
+
+// UpgradeProblem re-encodes a problem stored in any supported format with the current format.
+// If blob already uses the current format, it is returned as is and changed is false.
+func UpgradeProblem(blob []byte) (res []byte, changed bool, err error) {
+	ver, _ := binary.Uvarint(blob)
+	if ver == wireFormat {
+		return blob, false, nil
 	}
 
-	*p = Problem{
-		InputGenerator: gen,
-		OutputChecker:  che,
-		Steps:          int(steps),
-		Memory:         int(memory),
-		Instructions:   int(instr),
+	var p Problem
+	if err := p.UnmarshalBinary(blob); err != nil {
+		return nil, false, err
+	}
+	res, err = p.MarshalBinary()
+	if err != nil {
+		return nil, false, err
+	}
+	return res, true, nil
+}
+
+// appendString appends a length prefixed string.
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// readString reads a string appended by [appendString].
+func readString(r *bytes.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(r.Len()) {
+		return "", errors.New("string length is out of bounds")
+	}
+	text := make([]byte, n)
+	if _, err := io.ReadFull(r, text); err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+func checkTrailing(r *bytes.Reader) error {
+	if r.Len() > 0 {
+		return fmt.Errorf("buffer contains %d trailing junk bytes", r.Len())
 	}
 	return nil
 }
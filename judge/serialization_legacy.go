@@ -0,0 +1,80 @@
+package judge
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+// Decoding of problems stored in format v1.
+//
+// This format gob encodes components of a problem, so field names of the types below
+// and gob encodings of the component types are a part of the format.
+// They must never change, otherwise stored problems can not be read or upgraded anymore.
+
+type serializedGenerator struct {
+	List *listGenerator
+	BF   *bfGenerator
+	Lua  *luaGenerator
+}
+
+func unmarshalGenerator(dec *gob.Decoder) (InputGenerator, error) {
+	var val serializedGenerator
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	if val.Lua != nil {
+		return &smartGenerator{*val.Lua}, nil
+	}
+	if val.List != nil {
+		return *val.List, nil
+	}
+	if val.BF != nil {
+		return &smartGenerator{*val.BF}, nil
+	}
+	return nil, errors.New("value did not contain any known generator")
+}
+
+type serializedChecker struct {
+	List       *listSolution
+	BFSolution *bfSolution
+	BFChecker  *bfChecker
+	Lua        *lua.Checker
+}
+
+func unmarshalChecker(dec *gob.Decoder) (OutputChecker, error) {
+	var val serializedChecker
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	if val.Lua != nil {
+		return &luaChecker{*val.Lua}, nil
+	}
+	if val.BFChecker != nil {
+		return *val.BFChecker, nil
+	}
+	if val.BFSolution != nil {
+		return *val.BFSolution, nil
+	}
+	if val.List != nil {
+		return *val.List, nil
+	}
+	return nil, errors.New("value did not contain any known checker")
+}
+
+// unmarshalLegacy decodes a generator and a checker of a problem stored in format v1, that follow the header.
+func (p *Problem) unmarshalLegacy(r *bytes.Reader) error {
+	dec := gob.NewDecoder(r)
+
+	var err error
+	p.InputGenerator, err = unmarshalGenerator(dec)
+	if err != nil {
+		return err
+	}
+	p.OutputChecker, err = unmarshalChecker(dec)
+	return err
+}
@@ -0,0 +1,115 @@
+package judge_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+)
+
+// echoLine echoes a single line of input.
+const echoLine = ",----------[++++++++++.,----------]++++++++++."
+
+// Problems in testdata were written by format v1, the only one that used gob.
+// They must stay readable forever.
+func TestUpgradeProblem(t *testing.T) {
+	tests := []struct {
+		file      string
+		submition string
+	}{
+		{"problem_v1_bf.bin", echoLine},
+		{"problem_v1_bfchecker.bin", echoLine},
+		{"problem_v1_list.bin", echoLine},
+		{"problem_v1_lua.bin", echoLine},
+	}
+
+	j := judge.NewJudge(2)
+	defer j.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			legacy, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var old judge.Problem
+			if err := old.UnmarshalBinary(legacy); err != nil {
+				t.Fatalf("legacy problem: %v", err)
+			}
+
+			upgraded, changed, err := judge.UpgradeProblem(legacy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed || bytes.Equal(upgraded, legacy) {
+				t.Fatal("legacy problem was not upgraded")
+			}
+
+			if again, changed, err := judge.UpgradeProblem(upgraded); err != nil || changed || !bytes.Equal(again, upgraded) {
+				t.Errorf("upgraded problem was upgraded again: changed = %v, err = %v", changed, err)
+			}
+
+			var p judge.Problem
+			if err := p.UnmarshalBinary(upgraded); err != nil {
+				t.Fatalf("upgraded problem: %v", err)
+			}
+			if p.Steps != old.Steps || p.Memory != old.Memory || p.Instructions != old.Instructions || p.Time != old.Time ||
+				p.Dialect != old.Dialect || p.Scoring.String() != old.Scoring.String() || p.Comparison != old.Comparison ||
+				(p.Validator == nil) != (old.Validator == nil) || (p.Interactor == nil) != (old.Interactor == nil) {
+				t.Errorf("upgraded problem = %+v, want %+v", p, old)
+			}
+
+			// current format is deterministic
+			if b, err := p.MarshalBinary(); err != nil || !bytes.Equal(b, upgraded) {
+				t.Errorf("problem encoded differently: err = %v", err)
+			}
+
+			want, got := j.Judge(old, tt.submition), j.Judge(p, tt.submition)
+			if len(want) != len(got) {
+				t.Fatalf("got %d groups, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if len(want[i]) != len(got[i]) {
+					t.Fatalf("group %d: got %d tests, want %d", i, len(got[i]), len(want[i]))
+				}
+				for k := range want[i] {
+					if got[i][k].Status != want[i][k].Status || got[i][k].Comment != want[i][k].Comment {
+						t.Errorf("test %d.%d: got %v, want %v", i+1, k+1, got[i][k], want[i][k])
+					}
+				}
+			}
+			if judge.CalculateScore(got) != 1 {
+				t.Errorf("reference submission failed: %v", got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalProblemErrors(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("testdata", "problem_v1_lua.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, _, err = judge.UpgradeProblem(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]byte{
+		"empty":          nil,
+		"future version": {99},
+		"truncated":      valid[:len(valid)-1],
+		"trailing junk":  append(bytes.Clone(valid), 0),
+		"unknown kind":   bytes.Replace(valid, []byte("checker/lua"), []byte("checker/xyz"), 1),
+	}
+
+	for name, blob := range tests {
+		var p judge.Problem
+		if err := p.UnmarshalBinary(blob); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -17,11 +17,15 @@ func _() {
 	_ = x[StatusWrongAnswer-6]
 	_ = x[StatusCheckerFailed-7]
 	_ = x[StatusJudgeFailed-8]
+	_ = x[StatusInputExhausted-9]
+	_ = x[StatusOutputLimit-10]
+	_ = x[StatusSkipped-11]
+	_ = x[StatusPartial-12]
 }
 
-const _Status_name = "AcceptCompilationFailedRuntimeErrorSourceSizeLimitTimeLimitMemoryLimitWrongAnswerCheckerFailedJudgeFailed"
+const _Status_name = "AcceptCompilationFailedRuntimeErrorSourceSizeLimitTimeLimitMemoryLimitWrongAnswerCheckerFailedJudgeFailedInputExhaustedOutputLimitSkippedPartial"
 
-var _Status_index = [...]uint8{0, 6, 23, 35, 50, 59, 70, 81, 94, 105}
+var _Status_index = [...]uint8{0, 6, 23, 35, 50, 59, 70, 81, 94, 105, 119, 130, 137, 144}
 
 func (i Status) String() string {
 	if i >= Status(len(_Status_index)-1) {
@@ -0,0 +1,129 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+// StressConfig configures [Judge.Stress].
+type StressConfig struct {
+	Tests int   // Number of generated inputs. If not positive, 1000 inputs are generated.
+	Seed  int64 // Seed of the first input, following inputs use consecutive seeds.
+
+	Steps  int           // Maximum number of steps of a single run. If not positive, 1 million steps are allowed.
+	Memory int           // Maximum number of allocated bytes of a single run. If not positive, 64 MiB are allowed.
+	Time   time.Duration // Wall-clock time limit of a candidate run. If not positive, [DefaultTimeLimit] is used.
+
+	Dialect    bf.Dialect // Dialect both programs are executed with.
+	Comparison Comparison // How candidate output is compared with reference output.
+}
+
+// Counterexample is an input on which a candidate program disagrees with a reference program.
+// See [Judge.Stress].
+type Counterexample struct {
+	Seed    int64
+	Input   string
+	Verdict Verdict // Verdict of the candidate, reference failures are reported as [StatusCheckerFailed].
+
+	// Outputs of both programs, possibly incomplete if a program failed.
+	Reference string
+	Candidate string
+}
+
+// Stress runs reference and candidate brainfunk programs on inputs generated by gen and compares their outputs.
+// Inputs are judged concurrently by the worker pool of the judge.
+//
+// Returns the counterexample with the lowest seed, or nil if programs agreed on all inputs.
+// Error is returned if any program does not compile, input generation fails or ctx is done.
+func (j Judge) Stress(ctx context.Context, reference, candidate string, gen lua.SeedGenerator, cfg StressConfig) (*Counterexample, error) {
+	if cfg.Tests <= 0 {
+		cfg.Tests = 1000
+	}
+	if cfg.Steps <= 0 {
+		cfg.Steps = 1_000_000
+	}
+	if cfg.Memory <= 0 {
+		cfg.Memory = 64 << 20
+	}
+	if cfg.Time <= 0 {
+		cfg.Time = DefaultTimeLimit
+	}
+
+	refBC, err := bf.CompileDialect(reference, -1, cfg.Dialect)
+	if err != nil {
+		return nil, fmt.Errorf("reference: %w", err)
+	}
+	candBC, err := bf.CompileDialect(candidate, -1, cfg.Dialect)
+	if err != nil {
+		return nil, fmt.Errorf("candidate: %w", err)
+	}
+
+	inputs := make([]string, cfg.Tests)
+	for i := range inputs {
+		inputs[i], err = gen.Generate(cfg.Seed + int64(i))
+		if err != nil {
+			return nil, fmt.Errorf("seed %d: %w", cfg.Seed+int64(i), err)
+		}
+	}
+
+	p := Problem{
+		InputGenerator: listGenerator{inputs},
+		OutputChecker: bfSolution{
+			bc:     refBC,
+			steps:  cfg.Steps,
+			memory: cfg.Memory,
+		},
+		Instructions: -1,
+		Steps:        cfg.Steps,
+		Memory:       cfg.Memory,
+		Time:         cfg.Time,
+		Dialect:      cfg.Dialect,
+		Comparison:   cfg.Comparison,
+	}
+
+	// tests below next are done
+	done := make([]bool, len(inputs))
+	next := 0
+
+	first := -1
+	var verdict Verdict
+	for r := range j.Stream(ctx, p, candidate, false) {
+		done[r.Test] = true
+		if r.Verdict.Status != StatusAccept && (first < 0 || r.Test < first) {
+			first, verdict = r.Test, r.Verdict
+		}
+		for next < len(done) && done[next] {
+			next++
+		}
+		if first >= 0 && next >= first {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if first < 0 {
+		return nil, nil
+	}
+
+	return &Counterexample{
+		Seed:      cfg.Seed + int64(first),
+		Input:     inputs[first],
+		Verdict:   verdict,
+		Reference: runOutput(refBC, inputs[first], cfg.Steps, cfg.Memory),
+		Candidate: runOutput(candBC, inputs[first], cfg.Steps, cfg.Memory),
+	}, nil
+}
+
+// runOutput runs a program and returns its output, ignoring runtime errors.
+func runOutput(bc bf.ByteCode, input string, steps, memory int) string {
+	out := new(limitedBuffer)
+	s := bf.NewState(bc, strings.NewReader(input), out, steps, memory)
+	_ = s.Run()
+	return out.String()
+}
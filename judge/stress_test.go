@@ -0,0 +1,61 @@
+package judge_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+func TestStress(t *testing.T) {
+	gen, err := lua.NewSeedGenerator(`
+		function test_data(seed, params)
+			return tostring(random(1, 2)) .. tostring(random(1, 2))
+		end
+	`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const reference = `,.,.`
+
+	j := judge.NewJudge(4)
+	defer j.Close()
+
+	cfg := judge.StressConfig{Tests: 200, Seed: 10}
+
+	res, err := j.Stress(context.Background(), reference, `,>,<.>.`, gen, cfg)
+	if err != nil || res != nil {
+		t.Fatalf("equivalent programs: got %+v (err = %v) want no counterexample", res, err)
+	}
+
+	// prints the first byte twice
+	res, err = j.Stress(context.Background(), reference, `,..`, gen, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res == nil {
+		t.Fatal("expected a counterexample")
+	}
+
+	for seed := cfg.Seed; seed < res.Seed; seed++ {
+		input, err := gen.Generate(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if input[0] != input[1] {
+			t.Fatalf("seed %d with input %q is an earlier counterexample than %d", seed, input, res.Seed)
+		}
+	}
+	if res.Input[0] == res.Input[1] || res.Reference != res.Input || res.Candidate != res.Input[:1]+res.Input[:1] {
+		t.Errorf("got %+v", res)
+	}
+	if res.Verdict.Status != judge.StatusWrongAnswer {
+		t.Errorf("verdict %v want WrongAnswer", res.Verdict.Status)
+	}
+
+	if _, err := j.Stress(context.Background(), reference, `[`, gen, cfg); err == nil {
+		t.Error("expected a compilation error")
+	}
+}
@@ -1,6 +1,6 @@
 package judge
 
-import "fmt"
+import "time"
 
 //go:generate go tool golang.org/x/tools/cmd/stringer -type=Status -trimprefix=Status
 type Status uint
@@ -15,18 +15,37 @@ const (
 	StatusWrongAnswer
 	StatusCheckerFailed
 	StatusJudgeFailed
+	StatusInputExhausted // Submission tried to read past the end of input.
+	StatusOutputLimit    // Submission wrote more than [MaxOutput] bytes.
+	StatusSkipped        // Test was not executed, because another test of its group failed.
+	StatusPartial        // Output is partially correct, see [Verdict.Score].
 )
 
 type Verdict struct {
 	Status  Status
 	Comment string
+	Score   float64 // Partial credit in range (0, 1) of a [StatusPartial] verdict. See [Verdict.Credit].
+
+	// Difference between submission output and the reference answer of a [StatusWrongAnswer] verdict.
+	// It reveals the answer of the test, so unlike Comment it must not be shown to submission authors.
+	Detail string
+
+	Steps  int           // Number of steps the submission took on the test.
+	Memory int           // Number of tape bytes used by the submission, same as UsedMemory of the bf state.
+	Output int           // Number of bytes written by the submission.
+	Time   time.Duration // Wall-clock time spent executing the submission.
 }
 
+// Error formats status, comment and detail of a verdict.
 func (v Verdict) Error() string {
+	res := v.Status.String()
 	if v.Comment != "" {
-		return fmt.Sprintf("%v: %s", v.Status, v.Comment)
+		res += ": " + v.Comment
+	}
+	if v.Detail != "" {
+		res += ": " + v.Detail
 	}
-	return v.Status.String()
+	return res
 }
 
 type InputGenerator interface {
@@ -0,0 +1,71 @@
+package judge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TrueHopolok/braincode-/judge/bf"
+	"github.com/TrueHopolok/braincode-/judge/lua"
+)
+
+// InputValidator checks generated test input before any submission is judged against it.
+// If [Problem.Validator] is set, every test must pass validation, otherwise the whole submission
+// is reported as [StatusCheckerFailed] with the offending test.
+type InputValidator interface {
+	// ValidateInput returns a non nil error if input is invalid.
+	ValidateInput(input string) error
+}
+
+type luaValidator struct{ lua.Validator }
+
+// NewLuaValidator creates a new lua input validator.
+// See [lua.NewValidator] for details.
+func NewLuaValidator(source string) (InputValidator, error) {
+	v, err := lua.NewValidator(source)
+	return &luaValidator{v}, err
+}
+
+func (l *luaValidator) ValidateInput(input string) error {
+	return l.Validator.Validate(input)
+}
+
+type bfValidator bf.ByteCode
+
+// NewBFValidator creates a new brainfunk input validator.
+//
+// # Expected brainfunk API
+// Standard input will be test input followed by a 0.
+// No output signifies a valid input, any other output will be used as a reason of rejection.
+func NewBFValidator(source string) (InputValidator, error) {
+	bc, err := bf.Compile(source, -1)
+	return bfValidator(bc), err
+}
+
+func (b bfValidator) ValidateInput(input string) error {
+	out := new(bytes.Buffer)
+	s := bf.NewState(bf.ByteCode(b), strings.NewReader(input+"\x00"), out, 1e9, 64e6)
+	if err := s.Run(); err != nil {
+		return fmt.Errorf("validator failed: %w", err)
+	}
+	if out.Len() > 0 {
+		return errors.New(out.String())
+	}
+	return nil
+}
+
+// validateTests returns a verdict describing the first invalid test, or nil if all tests are valid.
+func validateTests(v InputValidator, tests [][]string) *Verdict {
+	for i, group := range tests {
+		for j, input := range group {
+			if err := v.ValidateInput(input); err != nil {
+				return &Verdict{
+					Status:  StatusCheckerFailed,
+					Comment: fmt.Sprintf("invalid test %d.%d: %v", i+1, j+1, err),
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package judge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/judge/ml"
+)
+
+func TestDocumentValidator(t *testing.T) {
+	const data = `
+.task = Digit
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {
+	{"1", "2"},
+	{"3", "42", "5"},
+}
+..
+
+.validator
+function validator(input)
+	inp:readInt(0, 9)
+	inp:expectEOF()
+end
+..
+`
+
+	doc, err := ml.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := judge.NewProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// validator must survive serialization
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if p.Validator == nil {
+		t.Fatal("validator was lost")
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	res := j.Judge(p, `,.`)
+	if len(res) != 1 || len(res[0]) != 1 {
+		t.Fatalf("expected a singular verdict, got %v", res)
+	}
+	v := res[0][0]
+	if v.Status != judge.StatusCheckerFailed || !strings.HasPrefix(v.Comment, "invalid test 2.2: ") {
+		t.Errorf("got %v (%v) want CheckerFailed for test 2.2", v.Status, v.Comment)
+	}
+}
+
+func TestBFValidator(t *testing.T) {
+	// prints the second byte of input, if there is one
+	v, err := judge.NewBFValidator(`,>,[.[-]]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := judge.Problem{
+		InputGenerator: judge.NewListGenerator([][]string{{"a", "b"}}),
+		OutputChecker:  judge.NewListSolutionSlice(judge.Pair{"a", "a"}, judge.Pair{"b", "b"}),
+		Validator:      v,
+	}
+
+	j := judge.NewJudge(1)
+	defer j.Close()
+
+	for _, group := range j.Judge(p, `,.`) {
+		for _, v := range group {
+			if v.Status != judge.StatusAccept {
+				t.Errorf("got %v (%v) want Accept", v.Status, v.Comment)
+			}
+		}
+	}
+
+	p.InputGenerator = judge.NewListGenerator([][]string{{"a", "bb"}})
+	res := j.Judge(p, `,.`)
+	if v := res[0][0]; v.Status != judge.StatusCheckerFailed || v.Comment != "invalid test 1.2: b" {
+		t.Errorf("got %v (%v) want CheckerFailed for test 1.2", v.Status, v.Comment)
+	}
+}
+
+func TestDocumentValidatorKind(t *testing.T) {
+	const task = `
+.task = Echo
+
+.steps = 10000
+.instructions = 100
+.memory = 200
+
+.lua
+function solution(input)
+	return input
+end
+
+test_data = {{"1"}}
+..
+`
+
+	cases := []struct {
+		name    string
+		blocks  string
+		wantErr bool
+	}{
+		{"lua", ".validator\nfunction validator(input) end\n..\n", false},
+		{"brainfunk", ".bfvalidator\n,[-]\n..\n", false},
+		{"brainfunk in lua block", ".validator\n,[-]\n..\n", true},
+		{"lua without validator function", ".validator\nlocal x = 1\n..\n", true},
+		{"both", ".validator\nfunction validator(input) end\n..\n.bfvalidator\n,[-]\n..\n", true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ml.Parse(strings.NewReader(task + tt.blocks))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := judge.NewProblem(doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && p.Validator == nil {
+				t.Error("validator was lost")
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ type Config struct {
 	DBname        string `default:"braincode"`
 	DBqueriesPath string `default:"server/db/queries/"`
 	Secure        bool   `default:"true"`
+	JudgeCache    int    `default:"1024"` // Number of judging results cached in memory.
+	JudgeCacheDB  bool   `default:"true"` // Also cache judging results in the database.
 	AdminsFile    string
 }
 
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -141,7 +142,7 @@ func TaskSolve(w http.ResponseWriter, r *http.Request) {
 	}
 	solution := r.PostFormValue("solution")
 
-	found, isvalid, err := models.SubmissionCreate(session.Get(r.Context()).Name, taskid, solution)
+	found, isvalid, err := models.SubmissionCreate(r.Context(), session.Get(r.Context()).Name, taskid, solution)
 	if err != nil {
 		errResp_Fatal(w, r, err)
 		return
@@ -170,9 +171,17 @@ func UploadPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Maximum size of an uploaded problem package.
+const packageSizeLimit = 16 << 20
+
 func TaskCreate(w http.ResponseWriter, r *http.Request) {
 	username := session.Get(r.Context()).Name
 
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		taskCreatePackage(w, r, username)
+		return
+	}
+
 	if err := r.ParseForm(); err != nil {
 		redirectErrorString(w, r, "invalid forma data: "+err.Error())
 		logger.Log.Debug("req=%p upload-err=%s ", r, err)
@@ -180,7 +189,32 @@ func TaskCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	v := r.FormValue("statement")
-	id, err := models.TaskCreate(strings.NewReader(v), username)
+	id, err := models.TaskCreate(r.Context(), strings.NewReader(v), username)
+	if err != nil {
+		redirectErrorString(w, r, "judge said no: "+err.Error())
+		logger.Log.Debug("req=%p upload-err=%s ", r, err)
+		return
+	}
+	http.Redirect(w, r, "/task/?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
+
+func taskCreatePackage(w http.ResponseWriter, r *http.Request, username string) {
+	r.Body = http.MaxBytesReader(w, r.Body, packageSizeLimit)
+	if err := r.ParseMultipartForm(packageSizeLimit); err != nil {
+		redirectErrorString(w, r, "invalid package: "+err.Error())
+		logger.Log.Debug("req=%p upload-err=%s ", r, err)
+		return
+	}
+
+	f, header, err := r.FormFile("package")
+	if err != nil {
+		redirectErrorString(w, r, "invalid package: "+err.Error())
+		logger.Log.Debug("req=%p upload-err=%s ", r, err)
+		return
+	}
+	defer f.Close()
+
+	id, err := models.TaskCreatePackage(r.Context(), f, header.Size, username)
 	if err != nil {
 		redirectErrorString(w, r, "judge said no: "+err.Error())
 		logger.Log.Debug("req=%p upload-err=%s ", r, err)
@@ -188,3 +222,31 @@ func TaskCreate(w http.ResponseWriter, r *http.Request) {
 	}
 	http.Redirect(w, r, "/task/?id="+strconv.Itoa(id), http.StatusSeeOther)
 }
+
+func TaskExport(w http.ResponseWriter, r *http.Request) {
+	staskid := r.URL.Query().Get("id")
+	taskid, err := strconv.Atoi(staskid)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid provided task-id=%s\nWant an integer", staskid), http.StatusNotAcceptable)
+		logger.Log.Debug("req=%p task-id=%s is not a valid integer", r, staskid)
+		return
+	}
+
+	// package is built in memory, so that errors can still be reported
+	var buf bytes.Buffer
+	found, err := models.TaskExportPackage(&buf, session.Get(r.Context()).Name, taskid)
+	if err != nil {
+		errResp_Fatal(w, r, fmt.Errorf("corrupted task: %w", err))
+		return
+	} else if !found {
+		http.Error(w, fmt.Sprintf("Invalid provided task-id=%d\nSuch task does not exists or is not yours", taskid), http.StatusNotAcceptable)
+		logger.Log.Debug("req=%p task-id=%d not found", r, taskid)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="task-%d.zip"`, taskid))
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.Log.Debug("req=%p export-err=%s", r, err)
+	}
+}
@@ -56,6 +56,20 @@ func SubmissionsAPI(w http.ResponseWriter, r *http.Request) {
 			logger.Log.Debug("req=%p submission-id=%s is not a valid integer", r, ssubid)
 			return
 		}
+		if r.URL.Query().Has("tests") {
+			// get results of every test of the submission
+			data, err := models.SubmissionFindTests(username, subid)
+			if err != nil {
+				errResp_Fatal(w, r, err)
+				return
+			}
+
+			if _, err = w.Write(data); err != nil {
+				errResp_Fatal(w, r, err)
+			}
+			return
+		}
+
 		solution, found, err := models.SubmissionFindOne(username, subid)
 		if err != nil {
 			errResp_Fatal(w, r, err)
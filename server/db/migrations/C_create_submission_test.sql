@@ -0,0 +1,13 @@
+CREATE TABLE SubmissionTest (
+	submission_id	INTEGER NOT NULL,
+	group_index		INTEGER NOT NULL,
+	test_index		INTEGER NOT NULL,
+	verdict			INT NOT NULL,
+	comment			TEXT NOT NULL,
+	steps			BIGINT NOT NULL,
+	memory			BIGINT NOT NULL,
+	output			BIGINT NOT NULL,
+	time_us			BIGINT NOT NULL,
+	PRIMARY KEY (submission_id, group_index, test_index),
+	FOREIGN KEY (submission_id) REFERENCES Submission(id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=INNODB;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE Submission
+ADD points DOUBLE NOT NULL DEFAULT 0,
+ADD max_points DOUBLE NOT NULL DEFAULT 0;
\ No newline at end of file
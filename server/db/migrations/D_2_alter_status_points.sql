@@ -0,0 +1,2 @@
+ALTER TABLE Status
+ADD points DOUBLE NOT NULL DEFAULT 0;
\ No newline at end of file
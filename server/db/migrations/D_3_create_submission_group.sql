@@ -0,0 +1,8 @@
+CREATE TABLE SubmissionGroup (
+	submission_id	INTEGER NOT NULL,
+	group_index		INTEGER NOT NULL,
+	points			DOUBLE NOT NULL,
+	max_points		DOUBLE NOT NULL,
+	PRIMARY KEY (submission_id, group_index),
+	FOREIGN KEY (submission_id) REFERENCES Submission(id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=INNODB;
\ No newline at end of file
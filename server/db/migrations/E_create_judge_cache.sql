@@ -0,0 +1,6 @@
+CREATE TABLE JudgeCache (
+	cache_key	BINARY(32) PRIMARY KEY,
+	task_id		INTEGER NOT NULL,
+	verdicts	MEDIUMBLOB NOT NULL,
+	FOREIGN KEY (task_id) REFERENCES Task(id) ON UPDATE CASCADE ON DELETE CASCADE
+) ENGINE=INNODB;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT IGNORE INTO JudgeCache (cache_key, task_id, verdicts)
+VALUES (?, ?, ?);
\ No newline at end of file
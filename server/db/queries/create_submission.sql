@@ -1,2 +1,2 @@
-INSERT INTO Submission (owner_name, task_id, verdict, comment, solution, score, timestamp)
-VALUES (?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
+INSERT INTO Submission (owner_name, task_id, verdict, comment, solution, score, points, max_points, timestamp)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
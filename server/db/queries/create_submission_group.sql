@@ -0,0 +1,2 @@
+INSERT INTO SubmissionGroup (submission_id, group_index, points, max_points)
+VALUES (?, ?, ?, ?);
\ No newline at end of file
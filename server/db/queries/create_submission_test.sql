@@ -0,0 +1,2 @@
+INSERT INTO SubmissionTest (submission_id, group_index, test_index, verdict, comment, steps, memory, output, time_us)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
\ No newline at end of file
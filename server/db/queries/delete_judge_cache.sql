@@ -0,0 +1,2 @@
+DELETE FROM JudgeCache
+WHERE task_id = ?;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT verdicts
+FROM JudgeCache
+WHERE cache_key = ?;
\ No newline at end of file
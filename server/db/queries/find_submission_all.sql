@@ -1,4 +1,4 @@
-SELECT s.id, s.TIMESTAMP, s.task_id, t.title_en, t.title_ru, s.score, COUNT(*) OVER() AS total_amount
+SELECT s.id, s.TIMESTAMP, s.task_id, t.title_en, t.title_ru, s.score, s.points, s.max_points, COUNT(*) OVER() AS total_amount
 FROM Submission AS s
 LEFT JOIN Task AS t
 ON s.task_id = t.id
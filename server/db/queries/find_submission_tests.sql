@@ -0,0 +1,6 @@
+SELECT t.group_index, t.test_index, t.verdict, t.comment, t.steps, t.memory, t.output, t.time_us
+FROM SubmissionTest AS t
+JOIN Submission AS s ON s.id = t.submission_id
+WHERE s.owner_name = ?
+AND s.id = ?
+ORDER BY t.group_index, t.test_index;
\ No newline at end of file
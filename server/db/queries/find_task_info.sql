@@ -0,0 +1,12 @@
+SELECT t.info
+FROM Task AS t
+WHERE t.id = ? AND (
+    t.owner_name = ?
+    OR
+    EXISTS (
+        SELECT *
+        FROM User AS u
+        WHERE u.name = ?
+        AND u.is_admin
+    )
+);
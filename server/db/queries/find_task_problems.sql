@@ -0,0 +1,2 @@
+SELECT id, problem
+FROM Task;
\ No newline at end of file
@@ -1,11 +1,5 @@
-INSERT INTO Status (owner_name, task_id, score)
-VALUES (?,?,?)
+INSERT INTO Status (owner_name, task_id, score, points)
+VALUES (?,?,?,?)
 ON DUPLICATE KEY UPDATE
-score = GREATEST(score, (
-	SELECT max_score FROM (
-		SELECT MAX(s.score) AS max_score
-		FROM Status AS s
-		WHERE s.owner_name = ?
-		AND s.task_id = ?
-	) AS sub
-));
\ No newline at end of file
+score = GREATEST(score, VALUES(score)),
+points = GREATEST(points, VALUES(points));
\ No newline at end of file
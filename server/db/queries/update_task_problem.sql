@@ -0,0 +1,3 @@
+UPDATE Task
+SET problem = ?
+WHERE id = ?;
\ No newline at end of file
@@ -40,6 +40,7 @@ func EnableControllerHandlers(mux *http.ServeMux) {
 
 	mux.Handle("GET /task/", session.MiddlewareFunc(controllers.TaskPage))
 	mux.Handle("POST /task/", session.AuthMiddlewareFunc(controllers.TaskSolve))
+	mux.Handle("GET /task/package/", session.AuthMiddlewareFunc(controllers.TaskExport))
 
 	mux.Handle("GET /login/", session.NoAuthMiddlewareFunc(controllers.LoginPage))
 	mux.Handle("POST /login/", session.NoAuthMiddlewareFunc(controllers.UserLogin))
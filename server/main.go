@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/TrueHopolok/braincode-/server/config"
 	db "github.com/TrueHopolok/braincode-/server/db"
 	logger "github.com/TrueHopolok/braincode-/server/logger"
+	"github.com/TrueHopolok/braincode-/server/models"
 	"github.com/TrueHopolok/braincode-/server/prepared"
 )
 
@@ -31,6 +35,14 @@ func main() {
 	}
 	logger.Log.Info("Migrations: execution succeeded")
 
+	//* Stored problems upgrade
+	logger.Log.Info("Problems: upgrading...")
+	if n, err := models.TaskUpgradeProblems(); err != nil {
+		logger.Log.Fatal("Problems: upgrade failed; error=%s", err)
+	} else {
+		logger.Log.Info("Problems: upgrade succeeded; upgraded=%d", n)
+	}
+
 	//* Templates init
 	logger.Log.Info("Templates: initilizating...")
 	if err := prepared.Init(); err != nil {
@@ -41,10 +53,19 @@ func main() {
 	// Error channel for all concurent threads
 	httpChan := make(chan error)
 
+	// Cancelled on exit to stop all in-flight requests (most importantly judging)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	//* HTTP init
 	logger.Log.Info("HTTP server: starting...")
+	srv := &http.Server{
+		Addr:        ":8080",
+		Handler:     MuxHTTP(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 	go func() {
-		httpChan <- http.ListenAndServe(":8080", MuxHTTP())
+		httpChan <- srv.ListenAndServe()
 	}()
 	select {
 	case err := <-httpChan:
@@ -78,4 +99,12 @@ func main() {
 	case <-quitChan:
 		logger.Log.Warn("Console: quitChan returned a value, server closing")
 	}
+
+	//* HTTP shutdown
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Log.Warn("HTTP server: shutdown failed; err=%s", err)
+	}
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+
+	"github.com/TrueHopolok/braincode-/judge"
+	"github.com/TrueHopolok/braincode-/server/config"
+	"github.com/TrueHopolok/braincode-/server/db"
+	"github.com/TrueHopolok/braincode-/server/logger"
+)
+
+// Judging results shared by all tasks, created lazily so that config is already parsed
+var memoryCache = sync.OnceValue(func() *judge.LRUCache {
+	return judge.NewLRUCache(config.Get().JudgeCache)
+})
+
+// Return a cache of judging results for selected task
+//
+// Results are stored in memory and, if enabled in config, in the database.
+// Cache keys include the problem blob, so updated task never gets stale results.
+func judgeCache(taskid int) judge.ResultCache {
+	if !config.Get().JudgeCacheDB {
+		return memoryCache()
+	}
+	return judge.TieredCache{memoryCache(), dbCache{taskid}}
+}
+
+// Database tier of the judging cache.
+// Entries are deleted together with their task, or when its problem is upgraded.
+// Database errors are logged and treated as cache misses.
+type dbCache struct {
+	taskid int
+}
+
+func (c dbCache) Get(key judge.CacheKey) ([][]judge.Verdict, bool) {
+	query, err := db.GetQuery("find_judge_cache")
+	if err != nil {
+		logger.Log.Error("judge cache: %v", err)
+		return nil, false
+	}
+
+	var raw []byte
+	if err := db.Conn.QueryRow(string(query), key[:]).Scan(&raw); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Log.Error("judge cache: %v", err)
+		}
+		return nil, false
+	}
+
+	var res [][]judge.Verdict
+	if err := json.Unmarshal(raw, &res); err != nil {
+		logger.Log.Warn("judge cache: task-id=%d corrupt entry: %v", c.taskid, err)
+		return nil, false
+	}
+	return res, true
+}
+
+func (c dbCache) Put(key judge.CacheKey, v [][]judge.Verdict) {
+	query, err := db.GetQuery("create_judge_cache")
+	if err != nil {
+		logger.Log.Error("judge cache: %v", err)
+		return
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		logger.Log.Error("judge cache: %v", err)
+		return
+	}
+
+	if _, err := db.Conn.Exec(string(query), key[:], c.taskid, raw); err != nil {
+		logger.Log.Error("judge cache: %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/TrueHopolok/braincode-/judge"
@@ -20,6 +22,18 @@ type SubmissionSet struct {
 	Rows        []SubmissionInfo
 }
 
+// SubmissionTest is a result of a submission on a single test.
+type SubmissionTest struct {
+	Group   int
+	Test    int
+	Verdict judge.Status
+	Comment string
+	Steps   int
+	Memory  int
+	Output  int
+	TimeUs  int64 // Wall-clock time in microseconds.
+}
+
 type SubmissionInfo struct {
 	Id        int
 	Timestamp string
@@ -27,6 +41,8 @@ type SubmissionInfo struct {
 	TitleEn   sql.NullString
 	TitleRu   sql.NullString
 	Score     float64
+	Points    float64
+	MaxPoints float64
 }
 
 // Return a solution for selected submission
@@ -56,6 +72,39 @@ func SubmissionFindOne(username string, subid int) (string, bool, error) {
 	return res, true, tx.Commit()
 }
 
+// Get results of every test of selected submission as encoded json slice
+func SubmissionFindTests(username string, subid int) ([]byte, error) {
+	query, err := db.GetQuery("find_submission_tests")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn.Query(string(query), username, subid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rawdata := []SubmissionTest{}
+	for rows.Next() {
+		var st SubmissionTest
+		err = rows.Scan(
+			&st.Group, &st.Test,
+			&st.Verdict, &st.Comment,
+			&st.Steps, &st.Memory,
+			&st.Output, &st.TimeUs)
+		if err != nil {
+			return nil, err
+		}
+		rawdata = append(rawdata, st)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rawdata)
+}
+
 func SubmissionFindLatest(username string, taskid int) (string, bool, error) {
 	query, err := db.GetQuery("find_submission_latest")
 	if err != nil {
@@ -105,6 +154,7 @@ func SubmissionFindAll(username string) ([]byte, error) {
 			&si.TitleEn,
 			&si.TitleRu,
 			&si.Score,
+			&si.Points, &si.MaxPoints,
 			&rawdata.TotalAmount)
 		if err != nil {
 			return nil, err
@@ -124,7 +174,10 @@ var globalJudge = judge.NewJudge(4)
 
 // Test and get a score for a given solution and given code, then saves it into database
 // Return false if solution is invalid and cannot be tested
-func SubmissionCreate(username string, taskid int, solution string) (found, isvalid bool, err error) {
+//
+// Judging is stopped once ctx is done, in that case submission is not saved.
+// Results of identical programs are reused, see judgeCache.
+func SubmissionCreate(ctx context.Context, username string, taskid int, solution string) (found, isvalid bool, err error) {
 	findTask, err := db.GetQuery("find_task_judge")
 	if err != nil {
 		return false, false, err
@@ -135,6 +188,16 @@ func SubmissionCreate(username string, taskid int, solution string) (found, isva
 		return false, false, err
 	}
 
+	createTest, err := db.GetQuery("create_submission_test")
+	if err != nil {
+		return false, false, err
+	}
+
+	createGroup, err := db.GetQuery("create_submission_group")
+	if err != nil {
+		return false, false, err
+	}
+
 	updateStatus, err := db.GetQuery("update_status")
 	if err != nil {
 		return false, false, err
@@ -155,26 +218,38 @@ func SubmissionCreate(username string, taskid int, solution string) (found, isva
 		logger.Log.Warn("task-id=%d corrupt entry", taskid)
 		return true, false, err
 	}
-	rawverdict := globalJudge.Judge(prb, solution)
+	rawverdict, cached := globalJudge.JudgeCached(ctx, judgeCache(taskid), rawprb, prb, solution)
+	if cached {
+		logger.Log.Debug("task-id=%d judging results found in cache", taskid)
+	}
+	if err = ctx.Err(); err != nil {
+		return true, true, err
+	}
+	breakdown := prb.Scoring.Score(rawverdict)
 	var (
 		verdict judge.Status = 0
 		comment string       = ""
-		score   float64      = 0
+		score   float64      = breakdown.Fraction()
 	)
+	if math.IsNaN(score) {
+		score = 0
+	}
+outer:
 	for i := range rawverdict {
-		for j := range rawverdict[i] {
-			verdict = rawverdict[i][j].Status
-			if verdict != judge.StatusAccept {
-				comment = rawverdict[i][j].Comment
-				break
+		for j, v := range rawverdict[i] {
+			if v.Status != judge.StatusAccept && v.Status != judge.StatusSkipped {
+				verdict, comment = v.Status, v.Comment
+				if verdict != judge.StatusCompilationFailed && verdict != judge.StatusSourceSizeLimit {
+					// compilation errors are not tied to any test
+					if seed, ok := judge.TestSeed(prb.InputGenerator, i, j); ok {
+						comment = fmt.Sprintf("test %d.%d (seed %d): %s", i+1, j+1, seed, comment)
+					} else {
+						comment = fmt.Sprintf("test %d.%d: %s", i+1, j+1, comment)
+					}
+				}
+				break outer
 			}
 		}
-		if comment != "" {
-			break
-		}
-	}
-	if comment == "" {
-		score = judge.CalculateScore(rawverdict)
 	}
 
 	tx, err := db.Conn.Begin()
@@ -187,6 +262,7 @@ func SubmissionCreate(username string, taskid int, solution string) (found, isva
 		username, taskid,
 		verdict, comment,
 		solution, score,
+		breakdown.Total(), breakdown.Max(),
 		time.Now())
 	if err != nil {
 		return true, true, err
@@ -198,8 +274,34 @@ func SubmissionCreate(username string, taskid int, solution string) (found, isva
 	if n != 1 {
 		return true, true, errors.New("invalid amount of inserted rows")
 	}
+	subid, err := res.LastInsertId()
+	if err != nil {
+		return true, true, err
+	}
+
+	for i := range rawverdict {
+		for j, v := range rawverdict[i] {
+			_, err = tx.Exec(string(createTest),
+				subid, i, j,
+				v.Status, v.Comment,
+				v.Steps, v.Memory,
+				v.Output, v.Time.Microseconds())
+			if err != nil {
+				return true, true, err
+			}
+		}
+	}
+
+	for i := range breakdown.Points {
+		_, err = tx.Exec(string(createGroup),
+			subid, i,
+			breakdown.Points[i], breakdown.MaxPoints[i])
+		if err != nil {
+			return true, true, err
+		}
+	}
 
-	res, err = tx.Exec(string(updateStatus), username, taskid, score, username, taskid)
+	res, err = tx.Exec(string(updateStatus), username, taskid, score, breakdown.Total())
 	if err != nil {
 		return true, true, err
 	}
@@ -207,8 +309,9 @@ func SubmissionCreate(username string, taskid int, solution string) (found, isva
 	if err != nil {
 		return true, true, err
 	}
-	if n < 0 || n > 1 {
-		return true, true, fmt.Errorf("updated %d rows, want 0 or 1", n)
+	// MySQL reports 2 affected rows when an existing row is updated
+	if n < 0 || n > 2 {
+		return true, true, fmt.Errorf("updated %d rows, want 0 to 2", n)
 	}
 
 	return true, true, tx.Commit()
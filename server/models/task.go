@@ -2,6 +2,7 @@ package models
 
 import (
 	"cmp"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/TrueHopolok/braincode-/judge"
 	"github.com/TrueHopolok/braincode-/judge/ml"
+	"github.com/TrueHopolok/braincode-/judge/pack"
 	"github.com/TrueHopolok/braincode-/server/db"
+	"github.com/TrueHopolok/braincode-/server/logger"
 )
 
 type Task struct {
@@ -148,11 +151,56 @@ func TaskFindAll(username, search string, currentUserOnly, isauth bool, page int
 	return jsondata, tx.Commit()
 }
 
-func TaskCreate(ioDoc io.Reader, username string) (int, error) {
+// Parses the task document, validates it and saves it into database.
+// Return id of the created task.
+//
+// Examples of the statement must be accepted by the checker of the task.
+// If the document has a reference solution, it must pass all tests, otherwise the task is rejected
+// with a per-test report. Validation is stopped once ctx is done, in that case task is not saved.
+func TaskCreate(ctx context.Context, ioDoc io.Reader, username string) (int, error) {
 	doc, err := ml.Parse(ioDoc)
 	if err != nil {
 		return 0, err
 	}
+	return taskCreate(ctx, doc, username)
+}
+
+// Reads the task from a zip problem package of given size, validates it and saves it into database.
+// Return id of the created task.
+//
+// See [pack] for the package layout, validation is the same as in [TaskCreate].
+func TaskCreatePackage(ctx context.Context, r io.ReaderAt, size int64, username string) (int, error) {
+	doc, err := pack.ReadZip(r, size)
+	if err != nil {
+		return 0, err
+	}
+	return taskCreate(ctx, doc, username)
+}
+
+// Writes the task as a zip problem package, that can be uploaded again with [TaskCreatePackage].
+// Only the owner of the task and admins can export it, for others the task is reported as not found.
+func TaskExportPackage(w io.Writer, username string, taskid int) (bool, error) {
+	query, err := db.GetQuery("find_task_info")
+	if err != nil {
+		return false, err
+	}
+
+	var rawDoc []byte
+	if err := db.Conn.QueryRow(string(query), taskid, username, username).Scan(&rawDoc); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var doc ml.Document
+	if err := doc.UnmarshalBinary(rawDoc); err != nil {
+		return true, err
+	}
+	return true, pack.WriteZip(w, doc)
+}
+
+func taskCreate(ctx context.Context, doc ml.Document, username string) (int, error) {
 	if doc.Localizations == nil {
 		return 0, errors.New("no valid task titles were provided - Empty map")
 	}
@@ -187,6 +235,21 @@ func TaskCreate(ioDoc io.Reader, username string) (int, error) {
 		return 0, err
 	}
 
+	if err = judge.CheckExamples(doc, prb); err != nil {
+		return 0, err
+	}
+
+	if ref := judge.ReferenceSolution(doc); ref != "" {
+		report := globalJudge.CheckReference(ctx, prb, ref)
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err = report.Err(); err != nil {
+			return 0, err
+		}
+		logger.Log.Info("task upload by %s: reference solution %s", username, report.Usage())
+	}
+
 	rawDoc, err := doc.MarshalBinary()
 	if err != nil {
 		return 0, err
@@ -227,3 +290,71 @@ func TaskCreate(ioDoc io.Reader, username string) (int, error) {
 
 	return int(rowid), tx.Commit()
 }
+
+// Re-encode problems of all tasks stored in older formats with the current format
+// Judge cache entries of upgraded tasks are deleted, since they can not be found with the new problem
+// Returns amount of upgraded tasks, corrupt tasks are logged and left as is
+func TaskUpgradeProblems() (int, error) {
+	findProblems, err := db.GetQuery("find_task_problems")
+	if err != nil {
+		return 0, err
+	}
+
+	updateProblem, err := db.GetQuery("update_task_problem")
+	if err != nil {
+		return 0, err
+	}
+
+	deleteCache, err := db.GetQuery("delete_judge_cache")
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	type row struct {
+		id      int
+		problem []byte
+	}
+	var upgraded []row
+
+	rows, err := tx.Query(string(findProblems))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.problem); err != nil {
+			return 0, err
+		}
+		res, changed, err := judge.UpgradeProblem(r.problem)
+		if err != nil {
+			logger.Log.Warn("task-id=%d corrupt entry: %s", r.id, err)
+			continue
+		}
+		if changed {
+			upgraded = append(upgraded, row{r.id, res})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	for _, r := range upgraded {
+		if _, err := tx.Exec(string(updateProblem), r.problem, r.id); err != nil {
+			return 0, err
+		}
+		// cache keys are derived from the problem blob, so entries of the old one are unreachable
+		if _, err := tx.Exec(string(deleteCache), r.id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(upgraded), tx.Commit()
+}
@@ -17,10 +17,14 @@ func TaskFindOne(w http.ResponseWriter, username string, isauth, isenglish bool,
 		prepared.T
 		Document ml.TemplatableDocument
 		Solution string
+		Id       int
+		Owner    bool
 	}{
 		t,
 		task.Doc.Templatable(t.Lang),
 		previousSolution,
+		task.General.Id,
+		isauth && username == task.General.OwnerName,
 	})
 	if err != nil {
 		return err